@@ -1,10 +1,212 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/Kaspetti/LayoutLearner/internal/corpus"
+	"github.com/Kaspetti/LayoutLearner/internal/dictionary"
 	"github.com/Kaspetti/LayoutLearner/internal/gamelogic"
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
+	"github.com/Kaspetti/LayoutLearner/internal/multiplayer"
+	"github.com/Kaspetti/LayoutLearner/internal/server"
 )
 
 
 func main() {
-    gamelogic.StartGame()
+    seed := flag.Int64("seed", 0, "the seed the words of the first lesson are generated from, random if 0")
+    profile := flag.String("profile", "", "the profile whose progress and settings are used, kept in profiles/<name>")
+    replay := flag.Int64("replay", 0, "the seed of a lesson in the history to play again as the first lesson")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-seed n] [-replay n] [-profile name] [host | join | serve | analyze | corpus] [flags]\n", os.Args[0])
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if *seed != 0 {
+        gamelogic.SetSeed(*seed)
+    }
+
+    if *replay != 0 {
+        gamelogic.SetReplay(*replay)
+    }
+
+    if *profile != "" {
+        if err := gamelogic.SetProfile(*profile); err != nil {
+            log.Fatalln(err)
+        }
+    }
+
+    args := flag.Args()
+    if len(args) == 0 {
+        if err := gamelogic.StartGame(); err != nil {
+            log.Fatalln(err)
+        }
+        return
+    }
+
+    var err error
+    switch args[0] {
+    case "host":
+        err = host(args[1:])
+    case "join":
+        err = join(args[1:])
+    case "serve":
+        err = serve(args[1:])
+    case "analyze":
+        err = analyze(args[1:])
+    case "corpus":
+        err = buildCorpus(args[1:])
+    default:
+        err = fmt.Errorf("unknown command %q, expected host, join, serve, analyze or corpus", args[0])
+    }
+
+    if err != nil {
+        log.Fatalln(err)
+    }
+}
+
+
+// host hosts a race on the local network which other players may join.
+func host(args []string) error {
+    fs := flag.NewFlagSet("host", flag.ExitOnError)
+    addr := fs.String("addr", net.JoinHostPort("", multiplayer.DefaultPort), "the address to host the race on")
+    name := fs.String("name", defaultName(), "the name shown to the other players")
+    fs.Parse(args)
+
+    return gamelogic.StartHostGame(*addr, *name)
+}
+
+
+// join joins a race hosted by another player.
+func join(args []string) error {
+    fs := flag.NewFlagSet("join", flag.ExitOnError)
+    addr := fs.String("addr", net.JoinHostPort("localhost", multiplayer.DefaultPort), "the address of the host")
+    name := fs.String("name", defaultName(), "the name shown to the other players")
+    fs.Parse(args)
+
+    return gamelogic.StartJoinGame(*addr, *name)
+}
+
+
+// serve serves the game engine over a local HTTP API.
+func serve(args []string) error {
+    fs := flag.NewFlagSet("serve", flag.ExitOnError)
+    addr := fs.String("addr", "localhost:8080", "the address to serve the API on")
+    fs.Parse(args)
+
+    return server.Serve(*addr)
+}
+
+
+// analyze compares keyboard layouts on a word list. The layouts are given as
+// built-in names or paths to layout files, and default to every built-in layout.
+func analyze(args []string) error {
+    fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+    wordsPath := fs.String("words", "resources/words.txt", "the word list to analyse, one word per line optionally followed by its count")
+    fs.Usage = func() {
+        fmt.Fprintf(fs.Output(), "Usage: %s analyze [-words path] [layout ...]\n", os.Args[0])
+        fs.PrintDefaults()
+    }
+    fs.Parse(args)
+
+    words, err := dictionary.ReadWordCounts(*wordsPath)
+    if err != nil {
+        return err
+    }
+
+    names := fs.Args()
+    if len(names) == 0 {
+        names = layout.BuiltinNames()
+    }
+
+    analyses := make([]layout.Analysis, len(names))
+    for i, name := range names {
+        l, err := layout.Load(name)
+        if err != nil {
+            return err
+        }
+        analyses[i] = l.Analyze(words)
+    }
+
+    return layout.WriteComparison(os.Stdout, analyses)
+}
+
+
+// buildCorpus builds a frequency dictionary from the files in a directory.
+// The dictionary may be used for the lessons with the dictionaryPath setting.
+func buildCorpus(args []string) error {
+    fs := flag.NewFlagSet("corpus", flag.ExitOnError)
+    output := fs.String("o", "", "the file to write the dictionary to, standard output if empty")
+    minLength := fs.Int("min", 2, "the min length of a word")
+    maxLength := fs.Int("max", 20, "the max length of a word, 0 for no limit")
+    minCount := fs.Int("count", 2, "the min amount of times a word must occur to be kept")
+    var ignore stringList
+    fs.Var(&ignore, "ignore", "a gitignore pattern of files or directories to skip, may be repeated")
+    fs.Usage = func() {
+        fmt.Fprintf(fs.Output(), "Usage: %s corpus [-o path] [-ignore pattern]... directory\n", os.Args[0])
+        fs.PrintDefaults()
+    }
+    fs.Parse(args)
+
+    if fs.NArg() != 1 {
+        fs.Usage()
+        return errors.New("expected a single directory to build the corpus from")
+    }
+
+    counts, err := corpus.Build(fs.Arg(0), corpus.Options{
+        Ignore: ignore,
+        MinLength: *minLength,
+        MaxLength: *maxLength,
+        MinCount: *minCount,
+    })
+    if err != nil {
+        return err
+    }
+
+    if *output == "" {
+        return dictionary.WriteWordCounts(os.Stdout, counts)
+    }
+
+    f, err := os.Create(*output)
+    if err != nil {
+        return err
+    }
+
+    if err := dictionary.WriteWordCounts(f, counts); err != nil {
+        f.Close()
+        return err
+    }
+
+    return f.Close()
+}
+
+
+// stringList is a flag which may be given several times.
+type stringList []string
+
+
+func (s *stringList) String() string {
+    return strings.Join(*s, ",")
+}
+
+
+func (s *stringList) Set(value string) error {
+    *s = append(*s, value)
+    return nil
+}
+
+
+// defaultName returns the name of the user running the game.
+func defaultName() string {
+    if name := os.Getenv("USER"); name != "" {
+        return name
+    }
+
+    return "player"
 }
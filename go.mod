@@ -2,15 +2,18 @@ module github.com/Kaspetti/LayoutLearner
 
 go 1.19
 
+require (
+	github.com/gdamore/tcell/v2 v2.6.1-0.20231203215052-2917c3801e73
+	github.com/rivo/tview v0.0.0-20231206124440-5f078138442e
+	golang.org/x/text v0.12.0
+)
+
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/gdamore/tcell v1.4.0 // indirect
-	github.com/gdamore/tcell/v2 v2.6.1-0.20231203215052-2917c3801e73 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.14 // indirect
-	github.com/rivo/tview v0.0.0-20231206124440-5f078138442e // indirect
 	github.com/rivo/uniseg v0.4.3 // indirect
 	golang.org/x/sys v0.11.0 // indirect
 	golang.org/x/term v0.9.0 // indirect
-	golang.org/x/text v0.12.0 // indirect
 )
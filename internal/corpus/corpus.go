@@ -0,0 +1,332 @@
+// Package corpus builds frequency dictionaries from the files a person types, such
+// as code, notes and emails, so that the characters and words of the lessons follow
+// what they actually write instead of the bundled word list.
+package corpus
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxFileSize is the max size of a file read into the corpus in bytes. Larger
+// files are most likely generated or data files and are skipped.
+const MaxFileSize = 1 << 20
+
+// IgnoreFile is the name of the files listing additional ignore patterns, one
+// per line. The patterns of the file in a directory apply to everything below it.
+const IgnoreFile = ".gitignore"
+
+
+// Options are the options for building a corpus.
+type Options struct {
+    Ignore      []string    // Patterns of the files and directories to skip, see Matcher
+    MinLength   int         // The min length of a word (inclusive)
+    MaxLength   int         // The max length of a word (inclusive), 0 for no limit
+    MinCount    int         // The min amount of times a word must occur to be kept
+}
+
+
+// Matcher matches paths against ignore patterns using the syntax of gitignore
+// files. A pattern with a slash at its start or middle is matched against the
+// path relative to the directory of the pattern, any other pattern against the
+// name of every file and directory below it. A pattern ending with a slash only
+// matches directories, "**" matches any amount of directories, and a pattern
+// starting with '!' includes what an earlier pattern ignored. The last matching
+// pattern decides.
+type Matcher struct {
+    rules       []rule
+}
+
+
+// rule is a single ignore pattern.
+type rule struct {
+    base        string      // The directory of the pattern relative to the root, empty for the root
+    pattern     string      // The pattern without the negation and trailing slash
+    negate      bool        // If the pattern includes the paths it matches
+    dirOnly     bool        // If the pattern only matches directories
+    anchored    bool        // If the pattern is matched against the path instead of the name
+}
+
+
+// NewMatcher returns a matcher for the patterns of the root directory.
+func NewMatcher(patterns []string) (*Matcher, error) {
+    m := &Matcher{}
+    if err := m.Add("", patterns); err != nil {
+        return nil, err
+    }
+
+    return m, nil
+}
+
+
+// Add adds the patterns of the directory at the given path relative to the
+// root, using slashes, after the patterns added before. Blank patterns and
+// comments starting with '#' are skipped.
+func (m *Matcher) Add(base string, patterns []string) error {
+    for _, pattern := range patterns {
+        pattern = strings.TrimSpace(pattern)
+        if pattern == "" || strings.HasPrefix(pattern, "#") {
+            continue
+        }
+
+        r := rule{ base: base }
+        if strings.HasPrefix(pattern, "!") {
+            r.negate = true
+            pattern = pattern[1:]
+        }
+        if strings.HasSuffix(pattern, "/") {
+            r.dirOnly = true
+            pattern = strings.TrimRight(pattern, "/")
+        }
+        r.anchored = strings.Contains(pattern, "/")
+        r.pattern = strings.TrimPrefix(pattern, "/")
+
+        for _, segment := range strings.Split(r.pattern, "/") {
+            if _, err := path.Match(segment, ""); err != nil {
+                return err
+            }
+        }
+        m.rules = append(m.rules, r)
+    }
+
+    return nil
+}
+
+
+// Match returns true if the path relative to the root, using slashes, should
+// be ignored.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+    ignored := false
+    for _, r := range m.rules {
+        if r.dirOnly && !isDir {
+            continue
+        }
+
+        rel := relPath
+        if r.base != "" {
+            if !strings.HasPrefix(relPath, r.base + "/") {
+                continue
+            }
+            rel = strings.TrimPrefix(relPath, r.base + "/")
+        }
+
+        var ok bool
+        if r.anchored {
+            ok = matchSegments(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+        } else {
+            ok, _ = path.Match(r.pattern, path.Base(rel))
+        }
+        if ok {
+            ignored = !r.negate
+        }
+    }
+
+    return ignored
+}
+
+
+// matchSegments returns if the segments of a path match the segments of a
+// pattern, where a "**" segment matches any amount of path segments.
+func matchSegments(pattern, segments []string) bool {
+    if len(pattern) == 0 {
+        return len(segments) == 0
+    }
+
+    if pattern[0] == "**" {
+        for i := 0; i <= len(segments); i++ {
+            if matchSegments(pattern[1:], segments[i:]) {
+                return true
+            }
+        }
+        return false
+    }
+
+    if len(segments) == 0 {
+        return false
+    }
+    if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+        return false
+    }
+
+    return matchSegments(pattern[1:], segments[1:])
+}
+
+
+// readIgnoreFile returns the patterns of the IgnoreFile in the directory, or
+// none if it has no such file.
+func readIgnoreFile(dir string) ([]string, error) {
+    data, err := os.ReadFile(filepath.Join(dir, IgnoreFile))
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, nil
+    } else if err != nil {
+        return nil, err
+    }
+
+    return strings.Split(string(data), "\n"), nil
+}
+
+
+// Build counts the words in every text file below the root directory. Hidden
+// files and directories, binary files and files larger than MaxFileSize are
+// skipped, as well as anything matching the ignore patterns in the options or
+// in the IgnoreFile of any directory above it. The patterns in the options can
+// not be negated by the ignore files.
+func Build(root string, options Options) (map[string]int, error) {
+    ignore, err := NewMatcher(options.Ignore)
+    if err != nil {
+        return nil, err
+    }
+    ignoreFiles, err := NewMatcher(nil)
+    if err != nil {
+        return nil, err
+    }
+
+    counts := make(map[string]int)
+    err = filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+
+        relPath, err := filepath.Rel(root, filePath)
+        if err != nil {
+            return err
+        }
+        relPath = filepath.ToSlash(relPath)
+
+        if filePath != root {
+            if strings.HasPrefix(d.Name(), ".") || ignore.Match(relPath, d.IsDir()) || ignoreFiles.Match(relPath, d.IsDir()) {
+                if d.IsDir() {
+                    return filepath.SkipDir
+                }
+                return nil
+            }
+        }
+
+        // Directories are visited before their contents, so the patterns of
+        // a directory are added before anything below it is matched
+        if d.IsDir() {
+            patterns, err := readIgnoreFile(filePath)
+            if err != nil {
+                return err
+            }
+
+            base := relPath
+            if filePath == root {
+                base = ""
+            }
+            return ignoreFiles.Add(base, patterns)
+        }
+        if !d.Type().IsRegular() {
+            return nil
+        }
+
+        info, err := d.Info()
+        if err != nil {
+            return err
+        }
+        if info.Size() > MaxFileSize {
+            return nil
+        }
+
+        return countFile(filePath, counts, options)
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    for word, count := range counts {
+        if count < options.MinCount {
+            delete(counts, word)
+        }
+    }
+
+    return counts, nil
+}
+
+
+// countFile adds the words of the file to the counts unless it is binary.
+func countFile(filePath string, counts map[string]int, options Options) error {
+    data, err := os.ReadFile(filePath)
+    if err != nil {
+        return err
+    }
+
+    if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+        return nil
+    }
+
+    return CountWords(bytes.NewReader(data), counts, options)
+}
+
+
+// CountWords adds the words read from r to the counts. A word is a run of
+// letters, and identifiers in camelCase or PascalCase are split into their
+// parts. Words are lowercased, and words outside the lengths in the options
+// are skipped.
+func CountWords(r io.Reader, counts map[string]int, options Options) error {
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 0, 64*1024), MaxFileSize)
+    scanner.Split(bufio.ScanWords)
+
+    for scanner.Scan() {
+        for _, word := range splitWords(scanner.Text()) {
+            length := utf8.RuneCountInString(word)
+            if length < options.MinLength || (options.MaxLength > 0 && length > options.MaxLength) {
+                continue
+            }
+
+            counts[word]++
+        }
+    }
+
+    return scanner.Err()
+}
+
+
+// splitWords splits the text into lowercased runs of letters, also splitting
+// where a lowercase letter is followed by an uppercase one and before the
+// last uppercase letter of an acronym followed by lowercase letters, so
+// "parseHTTPRequest" becomes "parse", "http" and "request".
+func splitWords(text string) []string {
+    chars := []rune(text)
+
+    var words []string
+    start := -1
+    for i, char := range chars {
+        if !unicode.IsLetter(char) {
+            if start >= 0 {
+                words = append(words, strings.ToLower(string(chars[start:i])))
+                start = -1
+            }
+            continue
+        }
+
+        if start >= 0 && unicode.IsUpper(char) {
+            previous := chars[i-1]
+            nextLower := i+1 < len(chars) && unicode.IsLower(chars[i+1])
+            if unicode.IsLower(previous) || (unicode.IsUpper(previous) && nextLower) {
+                words = append(words, strings.ToLower(string(chars[start:i])))
+                start = i
+            }
+        }
+
+        if start < 0 {
+            start = i
+        }
+    }
+
+    if start >= 0 {
+        words = append(words, strings.ToLower(string(chars[start:])))
+    }
+
+    return words
+}
@@ -0,0 +1,146 @@
+package corpus
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+    tests := []struct {
+        name        string
+        patterns    []string
+        base        string      // The directory the patterns are added for, empty for the root
+        path        string
+        isDir       bool
+        want        bool
+    }{
+        { name: "name pattern at the root", patterns: []string{ "*.log" }, path: "debug.log", want: true },
+        { name: "name pattern below the root", patterns: []string{ "*.log" }, path: "a/b/debug.log", want: true },
+        { name: "name pattern not matching", patterns: []string{ "*.log" }, path: "a/debug.txt", want: false },
+        { name: "comments and blank lines", patterns: []string{ "# *.log", "", "  " }, path: "debug.log", want: false },
+        { name: "directory pattern on a directory", patterns: []string{ "gen/" }, path: "a/gen", isDir: true, want: true },
+        { name: "directory pattern on a file", patterns: []string{ "gen/" }, path: "a/gen", want: false },
+        { name: "anchored pattern at the root", patterns: []string{ "/build" }, path: "build", isDir: true, want: true },
+        { name: "anchored pattern below the root", patterns: []string{ "/build" }, path: "lib/build", isDir: true, want: false },
+        { name: "pattern with a slash in the middle", patterns: []string{ "docs/*.md" }, path: "docs/a.md", want: true },
+        { name: "pattern with a slash in the middle below the root", patterns: []string{ "docs/*.md" }, path: "x/docs/a.md", want: false },
+        { name: "double star in the middle without directories", patterns: []string{ "docs/**/b" }, path: "docs/b", want: true },
+        { name: "double star in the middle with directories", patterns: []string{ "docs/**/b" }, path: "docs/x/y/b", want: true },
+        { name: "double star in the middle not matching", patterns: []string{ "docs/**/b" }, path: "src/x/b", want: false },
+        { name: "leading double star", patterns: []string{ "**/foo" }, path: "a/b/foo", want: true },
+        { name: "leading double star at the root", patterns: []string{ "**/foo" }, path: "foo", want: true },
+        { name: "negated pattern", patterns: []string{ "*.log", "!keep.log" }, path: "keep.log", want: false },
+        { name: "negated pattern on another path", patterns: []string{ "*.log", "!keep.log" }, path: "drop.log", want: true },
+        { name: "last matching pattern decides", patterns: []string{ "!keep.log", "*.log" }, path: "keep.log", want: true },
+        { name: "pattern of a directory below it", patterns: []string{ "*.go" }, base: "src", path: "src/a/main.go", want: true },
+        { name: "pattern of a directory outside of it", patterns: []string{ "*.go" }, base: "src", path: "lib/main.go", want: false },
+        { name: "anchored pattern of a directory", patterns: []string{ "/gen" }, base: "src", path: "src/gen", isDir: true, want: true },
+        { name: "anchored pattern of a directory deeper down", patterns: []string{ "/gen" }, base: "src", path: "src/a/gen", isDir: true, want: false },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            m, err := NewMatcher(nil)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if err := m.Add(test.base, test.patterns); err != nil {
+                t.Fatal(err)
+            }
+
+            if got := m.Match(test.path, test.isDir); got != test.want {
+                t.Errorf("Match(%q, %t) with %q in %q = %t, want %t", test.path, test.isDir, test.patterns, test.base, got, test.want)
+            }
+        })
+    }
+}
+
+
+func TestMatcherInvalidPattern(t *testing.T) {
+    if _, err := NewMatcher([]string{ "a/[b" }); err == nil {
+        t.Error("NewMatcher succeeded with a malformed pattern, want an error")
+    }
+}
+
+
+func TestBuild(t *testing.T) {
+    root := t.TempDir()
+    files := map[string]string{
+        "main.go":              "alpha",
+        "notes.txt":            "beta",
+        "debug.log":            "gamma",
+        "keep.log":             "delta",
+        ".hidden/a.txt":        "epsilon",
+        "gen/a.go":             "zeta",
+        "src/a.txt":            "eta",
+        "src/b.go":             "theta",
+        "src/.gitignore":       "*.go\n",
+        ".gitignore":           "*.log\n!keep.log\ngen/\n",
+    }
+    for name, content := range files {
+        filePath := filepath.Join(root, filepath.FromSlash(name))
+        if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    got, err := Build(root, Options{ Ignore: []string{ "notes.txt" } })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    want := map[string]int{ "alpha": 1, "delta": 1, "eta": 1 }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("Build() = %v, want %v", got, want)
+    }
+}
+
+
+func TestCountWords(t *testing.T) {
+    tests := []struct {
+        name        string
+        text        string
+        options     Options
+        want        map[string]int
+    }{
+        {
+            name: "words and punctuation",
+            text: "The sea, the sea!",
+            want: map[string]int{ "the": 2, "sea": 2 },
+        },
+        {
+            name: "camel and pascal case",
+            text: "parseHTTPRequest XMLParser",
+            want: map[string]int{ "parse": 1, "http": 1, "request": 1, "xml": 1, "parser": 1 },
+        },
+        {
+            name: "lengths",
+            text: "a be sea rise",
+            options: Options{ MinLength: 2, MaxLength: 3 },
+            want: map[string]int{ "be": 1, "sea": 1 },
+        },
+        {
+            name: "characters outside of ascii",
+            text: "Café été",
+            want: map[string]int{ "café": 1, "été": 1 },
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            got := make(map[string]int)
+            if err := CountWords(strings.NewReader(test.text), got, test.options); err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(got, test.want) {
+                t.Errorf("CountWords(%q) = %v, want %v", test.text, got, test.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,106 @@
+// Package course contains structured courses of predefined lessons. A course is
+// a list of stages practised in order, and a stage is passed by finishing one of
+// its lessons at its target accuracy and speed.
+package course
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Course is an ordered list of stages shared as a JSON file.
+type Course struct {
+    Name        string      `json:"name"`           // The name of the course, progress is kept per name
+    Stages      []Stage     `json:"stages"`         // The stages of the course in the order they are practised
+}
+
+
+// Stage is a single step of a course.
+type Stage struct {
+    Name        string      `json:"name"`           // The name of the stage
+    Characters  string      `json:"characters"`     // The characters the words of the lessons are made of
+    Text        string      `json:"text"`           // A fixed text typed in every lesson instead of generated words, optional
+    Accuracy    float64     `json:"accuracy"`       // The accuracy from 0 to 1 a lesson needs to pass the stage
+    WPM         float64     `json:"wpm"`            // The words per minute a lesson needs to pass the stage
+}
+
+
+// Load loads the course from the JSON file at the given path.
+func Load(path string) (*Course, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    var c Course
+    if err := json.Unmarshal(data, &c); err != nil {
+        return nil, fmt.Errorf("parsing course %s: %w", path, err)
+    }
+
+    if c.Name == "" {
+        c.Name = path
+    }
+
+    if err := c.init(); err != nil {
+        return nil, fmt.Errorf("course %s: %w", path, err)
+    }
+
+    return &c, nil
+}
+
+
+// init validates the stages of the course. Stages with a fixed text and no
+// characters are made of the characters of the text.
+func (c *Course) init() error {
+    if len(c.Stages) == 0 {
+        return fmt.Errorf("the course has no stages")
+    }
+
+    for i := range c.Stages {
+        stage := &c.Stages[i]
+        if stage.Name == "" {
+            stage.Name = fmt.Sprintf("Stage %d", i + 1)
+        }
+
+        if stage.Characters == "" {
+            stage.Characters = textCharacters(stage.Text)
+        }
+        if stage.Characters == "" {
+            return fmt.Errorf("stage %q needs characters or a text", stage.Name)
+        }
+
+        if stage.Accuracy < 0 || stage.Accuracy > 1 {
+            return fmt.Errorf("the accuracy of stage %q must be between 0 and 1, got %v", stage.Name, stage.Accuracy)
+        }
+        if stage.WPM < 0 {
+            return fmt.Errorf("the wpm of stage %q must not be negative, got %v", stage.Name, stage.WPM)
+        }
+    }
+
+    return nil
+}
+
+
+// Passed returns if a lesson typed at the given words per minute and
+// accuracy passes the stage.
+func (s Stage) Passed(wpm, accuracy float64) bool {
+    return wpm >= s.WPM && accuracy >= s.Accuracy
+}
+
+
+// textCharacters returns the distinct characters of a text other than
+// whitespace, in the order they first appear.
+func textCharacters(text string) string {
+    var chars strings.Builder
+    seen := make(map[rune]bool)
+    for _, char := range strings.Join(strings.Fields(text), "") {
+        if !seen[char] {
+            seen[char] = true
+            chars.WriteRune(char)
+        }
+    }
+
+    return chars.String()
+}
@@ -0,0 +1,85 @@
+package course
+
+import (
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+    tests := []struct {
+        name            string
+        stages          []Stage
+        wantErr         bool
+        wantName        string
+        wantCharacters  string
+    }{
+        {
+            name: "characters",
+            stages: []Stage{ { Name: "Home row", Characters: "asdf", Accuracy: 0.9, WPM: 20 } },
+            wantName: "Home row",
+            wantCharacters: "asdf",
+        },
+        {
+            name: "default name",
+            stages: []Stage{ { Characters: "asdf" } },
+            wantName: "Stage 1",
+            wantCharacters: "asdf",
+        },
+        {
+            name: "characters of the text",
+            stages: []Stage{ { Text: "sad  dad\nfads" } },
+            wantName: "Stage 1",
+            wantCharacters: "sadf",
+        },
+        {
+            name: "characters of the text outside of ascii",
+            stages: []Stage{ { Text: "été café" } },
+            wantName: "Stage 1",
+            wantCharacters: "étcaf",
+        },
+        { name: "no stages", stages: nil, wantErr: true },
+        { name: "no characters or text", stages: []Stage{ { Text: " \n " } }, wantErr: true },
+        { name: "accuracy too low", stages: []Stage{ { Characters: "a", Accuracy: -0.1 } }, wantErr: true },
+        { name: "accuracy too high", stages: []Stage{ { Characters: "a", Accuracy: 1.1 } }, wantErr: true },
+        { name: "negative wpm", stages: []Stage{ { Characters: "a", WPM: -1 } }, wantErr: true },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            c := Course{ Name: "test", Stages: test.stages }
+            err := c.init()
+            if (err != nil) != test.wantErr {
+                t.Fatalf("init() error = %v, want error %t", err, test.wantErr)
+            }
+            if test.wantErr {
+                return
+            }
+
+            stage := c.Stages[0]
+            if stage.Name != test.wantName || stage.Characters != test.wantCharacters {
+                t.Errorf("stage %q with characters %q, want %q with %q", stage.Name, stage.Characters, test.wantName, test.wantCharacters)
+            }
+        })
+    }
+}
+
+
+func TestPassed(t *testing.T) {
+    stage := Stage{ Characters: "asdf", Accuracy: 0.95, WPM: 30 }
+
+    tests := []struct {
+        wpm         float64
+        accuracy    float64
+        want        bool
+    }{
+        { wpm: 30, accuracy: 0.95, want: true },
+        { wpm: 45, accuracy: 1, want: true },
+        { wpm: 29.9, accuracy: 1, want: false },
+        { wpm: 45, accuracy: 0.94, want: false },
+    }
+
+    for _, test := range tests {
+        if got := stage.Passed(test.wpm, test.accuracy); got != test.want {
+            t.Errorf("Passed(%v, %v) = %t, want %t", test.wpm, test.accuracy, got, test.want)
+        }
+    }
+}
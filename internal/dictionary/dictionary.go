@@ -6,59 +6,79 @@ package dictionary
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"math/rand"
-	"os"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 
-// GetCharacterPriority returns a list of character priorities for each character in a
-// dictionary given the path of the dictionary file.
-func GetCharacterPriority(dictionaryPath string) ([]rune, error) {
-    f, err := os.Open(dictionaryPath)
+// ReadWordCounts returns how often each word of a dictionary occurs given the path of
+// the dictionary file. Each line holds a word, optionally followed by its count. Words
+// without a count occur once.
+func ReadWordCounts(dictionaryPath string) (map[string]int, error) {
+    d, err := Load(dictionaryPath, Filter{})
     if err != nil {
         return nil, err
-    } 
-    defer f.Close()
-
-    scanner := bufio.NewScanner(f)
-    characterOccurences := make(map[rune]int)
-    totalCharacterCount := 0
-
-    for scanner.Scan() {
-        word := strings.ToLower(scanner.Text())
-        for _, char := range word {
-            if occurence, ok := characterOccurences[char]; ok {
-                characterOccurences[char] = occurence + 1
-            } else {
-                characterOccurences[char] = 1
-            }
-            totalCharacterCount += 1
+    }
+
+    return d.WordCounts(), nil
+}
+
+
+// WriteWordCounts writes the words and their counts in the dictionary format read by
+// ReadWordCounts, most frequent first.
+func WriteWordCounts(w io.Writer, counts map[string]int) error {
+    words := make([]string, 0, len(counts))
+    for word := range counts {
+        words = append(words, word)
+    }
+    sort.Slice(words, func(i, j int) bool {
+        if counts[words[i]] != counts[words[j]] {
+            return counts[words[i]] > counts[words[j]]
         }
+        return words[i] < words[j]
+    })
+
+    bw := bufio.NewWriter(w)
+    for _, word := range words {
+        fmt.Fprintf(bw, "%s %d\n", word, counts[word])
     }
 
-    characters := make([]rune, len(characterOccurences)) 
-    i := 0
-    for char := range characterOccurences {
-        characters[i] = char
-        i += 1
+    return bw.Flush()
+}
+
+
+// parseLine returns the word on a line of a dictionary and its count. The word is
+// empty for blank lines.
+func parseLine(line string) (string, int, error) {
+    fields := strings.Fields(line)
+    if len(fields) == 0 {
+        return "", 0, nil
     }
 
-    sort.Slice(characters, func(i, j int) bool {
-        return characterOccurences[characters[i]] > characterOccurences[characters[j]]
-    })
+    count := 1
+    if len(fields) > 1 {
+        var err error
+        count, err = strconv.Atoi(fields[1])
+        if err != nil || count < 0 {
+            return "", 0, fmt.Errorf("invalid word count %q", fields[1])
+        }
+    }
 
-    return characters, nil
+    return fields[0], count, nil
 }
 
 
 // GenerateWord generates a random word using the characters provided. The caller may choose the
-// length of the word and a priority character. The priority character is guaranteed to be within
-// the word. 
-func GenerateWord(chars []rune, priorityCharacter rune, minLength, maxLength int) string {
-    length := rand.Intn(maxLength-minLength) + minLength
-    priorityPosition := rand.Intn(length)
+// length of the word, where both bounds are inclusive, and a priority character. The priority
+// character is guaranteed to be within the word. All randomness is drawn from rng, so the same
+// source gives the same word.
+func GenerateWord(rng *rand.Rand, chars []rune, priorityCharacter rune, minLength, maxLength int) string {
+    length := rng.Intn(maxLength-minLength+1) + minLength
+    priorityPosition := rng.Intn(length)
 
     charsUsed := make(map[rune]int)
     for _, char := range chars {
@@ -80,7 +100,7 @@ func GenerateWord(chars []rune, priorityCharacter rune, minLength, maxLength int
         if charInARow == 2 {
             excludeChar = previousCharacter
         }
-        char := getRandomCharacter(chars, charsUsed, length/2, excludeChar)
+        char := getRandomCharacter(rng, chars, charsUsed, length/2, excludeChar)
 
         // Makes sure the loop breaks if there are no characters possible to use
         if char == ' ' {
@@ -105,7 +125,7 @@ func GenerateWord(chars []rune, priorityCharacter rune, minLength, maxLength int
 
 // getRandomCharacter gets a random character from chars which has not been used more
 // than maxUsage.
-func getRandomCharacter(chars []rune, charsUsed map[rune]int, maxUsage int, exclude rune) rune {
+func getRandomCharacter(rng *rand.Rand, chars []rune, charsUsed map[rune]int, maxUsage int, exclude rune) rune {
     availableChars := make([]rune, 0)
     for _, char := range chars {
         if char == exclude {
@@ -118,66 +138,75 @@ func getRandomCharacter(chars []rune, charsUsed map[rune]int, maxUsage int, excl
     }
 
     if len(availableChars) > 0 {
-        return availableChars[rand.Intn(len(availableChars))]
+        return availableChars[rng.Intn(len(availableChars))]
     } else {
         return ' '
     }
 }
 
 
-// GetWordsFromChars gets "amount" of words from the dictionary passed to it which use only the characters in "chars",  
-// which contain the "priorityChar" and satisfy the min and max length.
-func GetWordsFromChars(dictionaryPath string, chars []rune, priorityChar rune, minLength, maxLength, amount int) ([]string, error) {
-    f, err := os.Open(dictionaryPath)
-    if err != nil {
-        return nil, err
+// PickWords picks "amount" of the given words at random with a probability proportional to the weight
+// of each word. Words may be picked more than once.
+func PickWords(rng *rand.Rand, words []string, weights []float64, amount int) []string {
+    if len(words) == 0 {
+        return nil
     }
-    defer f.Close()
 
-    charsSet := make(map[rune]bool)
-    for _, char := range chars {
-        charsSet[char] = true
+    picked := make([]string, amount)
+    for i := range picked {
+        picked[i] = words[pickWeighted(rng, weights)]
     }
 
-    words := make([]string, 0)
+    return picked
+}
 
-    scanner := bufio.NewScanner(f)
-    for scanner.Scan() {
-        priorityFound := false
-        invalidChar := false
-        word := strings.ToLower(scanner.Text())
 
-        if len(word) > maxLength || len(word) < minLength {
-            continue
-        }
-
-        for _, char := range word {
-            if !charsSet[char] {
-                invalidChar = true
-                break
-            }
+// scoreWord returns the sum of the weights of the distinct target characters in the word. Returns false if
+// the word uses characters outside of charsSet or contains none of the targets.
+func scoreWord(word string, charsSet map[rune]bool, targets map[rune]float64) (float64, bool) {
+    score := 0.0
+    found := make(map[rune]bool)
 
-            if char == priorityChar {
-                priorityFound = true
-            }
+    for _, char := range word {
+        if !charsSet[char] {
+            return 0, false
         }
 
-        if !invalidChar && priorityFound {
-            words = append(words, word)
+        if weight, ok := targets[char]; ok && !found[char] {
+            found[char] = true
+            score += weight
         }
     }
 
-    if len(words) < 4 {
-        for i := 0; i < 4 - len(words); i++ {
-            words = append(words, GenerateWord(chars, priorityChar, minLength, maxLength))
+    return score, len(found) > 0
+}
+
+
+// pickWeighted returns a random index of weights with a probability proportional to its weight. If no
+// weight is positive every index is equally likely.
+func pickWeighted(rng *rand.Rand, weights []float64) int {
+    total := 0.0
+    for _, weight := range weights {
+        if weight > 0 {
+            total += weight
         }
     }
 
-    selectedWords := make([]string, amount)
-    for i := 0; i < amount; i++ {
-        selectedWords[i] = words[rand.Intn(len(words))]
+    if total <= 0 {
+        return rng.Intn(len(weights))
     }
 
+    r := rng.Float64() * total
+    for i, weight := range weights {
+        if weight <= 0 {
+            continue
+        }
+
+        r -= weight
+        if r < 0 {
+            return i
+        }
+    }
 
-    return selectedWords, nil
+    return len(weights) - 1
 }
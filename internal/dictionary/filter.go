@@ -0,0 +1,159 @@
+package dictionary
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Filter selects which words of a word list are kept when it is loaded and
+// how they are normalised. The zero value keeps every word.
+type Filter struct {
+    Locale      string      `json:"locale"`     // The BCP 47 tag of the language words are lowercased in, e.g. "tr", language neutral if empty
+    Blocklist   string      `json:"blocklist"`  // The path of a list of words to leave out
+    Profanity   string      `json:"profanity"`  // The path of a list of profane words to leave out
+    MinCount    int         `json:"minCount"`   // The min amount of times a word must occur to be kept
+    MaxCount    int         `json:"maxCount"`   // The max amount of times a word may occur to be kept, 0 for no limit
+    Include     string      `json:"include"`    // A regular expression words must match to be kept, every word if empty
+    Exclude     string      `json:"exclude"`    // A regular expression of words to leave out
+}
+
+
+// wordFilter is a compiled Filter.
+type wordFilter struct {
+    filter      Filter
+    caser       cases.Caser
+    blocked     wordList
+    profane     wordList
+    include     *regexp.Regexp
+    exclude     *regexp.Regexp
+}
+
+// wordList is a list of words read from a file. Entries ending with '*'
+// match every word starting with the rest of the entry.
+type wordList struct {
+    words       map[string]bool
+    prefixes    []string
+}
+
+
+// compile parses the locale and regular expressions of the filter and reads
+// its word lists.
+func (f Filter) compile() (*wordFilter, error) {
+    if f.MinCount < 0 || f.MaxCount < 0 || (f.MaxCount > 0 && f.MaxCount < f.MinCount) {
+        return nil, fmt.Errorf("invalid word count range %d to %d", f.MinCount, f.MaxCount)
+    }
+
+    tag := language.Und
+    if f.Locale != "" {
+        var err error
+        tag, err = language.Parse(f.Locale)
+        if err != nil {
+            return nil, fmt.Errorf("invalid locale %q: %w", f.Locale, err)
+        }
+    }
+
+    wf := &wordFilter{ filter: f, caser: cases.Lower(tag) }
+
+    var err error
+    if wf.blocked, err = wf.readWordList(f.Blocklist); err != nil {
+        return nil, err
+    }
+    if wf.profane, err = wf.readWordList(f.Profanity); err != nil {
+        return nil, err
+    }
+
+    if f.Include != "" {
+        if wf.include, err = regexp.Compile(f.Include); err != nil {
+            return nil, fmt.Errorf("invalid include pattern: %w", err)
+        }
+    }
+    if f.Exclude != "" {
+        if wf.exclude, err = regexp.Compile(f.Exclude); err != nil {
+            return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+        }
+    }
+
+    return wf, nil
+}
+
+
+// normalize trims the word, composes it to NFC and lowercases it in the
+// locale of the filter, so different spellings of a word are merged.
+func (wf *wordFilter) normalize(word string) string {
+    return wf.caser.String(norm.NFC.String(strings.TrimSpace(word)))
+}
+
+
+// keep returns true if a word occurring count times passes the filter.
+func (wf *wordFilter) keep(word string, count int) bool {
+    if count < wf.filter.MinCount || (wf.filter.MaxCount > 0 && count > wf.filter.MaxCount) {
+        return false
+    }
+
+    if wf.blocked.contains(word) || wf.profane.contains(word) {
+        return false
+    }
+
+    if wf.include != nil && !wf.include.MatchString(word) {
+        return false
+    }
+
+    return wf.exclude == nil || !wf.exclude.MatchString(word)
+}
+
+
+// readWordList reads the normalised words of the file at the given path, one
+// per line. Blank lines and lines starting with '#' are skipped. An empty
+// path gives an empty list.
+func (wf *wordFilter) readWordList(listPath string) (wordList, error) {
+    list := wordList{ words: make(map[string]bool) }
+    if listPath == "" {
+        return list, nil
+    }
+
+    f, err := os.Open(listPath)
+    if err != nil {
+        return list, err
+    }
+    defer f.Close()
+
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        entry := wf.normalize(scanner.Text())
+        if entry == "" || strings.HasPrefix(entry, "#") {
+            continue
+        }
+
+        if strings.HasSuffix(entry, "*") {
+            list.prefixes = append(list.prefixes, strings.TrimSuffix(entry, "*"))
+        } else {
+            list.words[entry] = true
+        }
+    }
+
+    return list, scanner.Err()
+}
+
+
+// contains returns true if the word is in the list or starts with one of
+// its prefixes.
+func (l wordList) contains(word string) bool {
+    if l.words[word] {
+        return true
+    }
+
+    for _, prefix := range l.prefixes {
+        if strings.HasPrefix(word, prefix) {
+            return true
+        }
+    }
+
+    return false
+}
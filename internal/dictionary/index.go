@@ -0,0 +1,348 @@
+package dictionary
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// overflowBit is the bit of the character set masks shared by every character
+// which is not among the 63 most frequent characters of the dictionary. Words
+// with this bit set are checked character by character.
+const overflowBit = 63
+
+
+// Dictionary is a word list loaded into memory. Words are indexed by their
+// length and the set of characters they use, so finding the words which only
+// use a given set of characters does not require scanning every word.
+type Dictionary struct {
+    Paths       []string                    // The paths of the word lists the dictionary was loaded from
+    Filter      Filter                      // The filter applied to the words when they were loaded
+    words       []string                    // The distinct words in the order they first appear
+    counts      []int                       // How often each word occurs
+    priority    []rune                      // The characters sorted by how often they occur, most frequent first
+    bits        map[rune]uint               // The bit of each character in the character set masks
+    index       map[int][]wordGroup         // The words of each length grouped by their character set
+}
+
+// wordGroup is the words of a dictionary which have the same length and use
+// the same set of characters.
+type wordGroup struct {
+    mask        uint64                      // The character set of the words
+    words       []int                       // The indices of the words in the dictionary
+}
+
+
+// loadKey identifies a dictionary kept in memory by Load.
+type loadKey struct {
+    path        string
+    filter      Filter
+}
+
+
+var (
+    loadedMu    sync.Mutex
+    loaded      = make(map[loadKey]*Dictionary)
+)
+
+
+// Load returns the dictionary at the given path with the filter applied. The
+// file is only read the first time, after which the dictionary is kept in memory.
+func Load(dictionaryPath string, filter Filter) (*Dictionary, error) {
+    loadedMu.Lock()
+    defer loadedMu.Unlock()
+
+    key := loadKey{ path: dictionaryPath, filter: filter }
+    if d, ok := loaded[key]; ok {
+        return d, nil
+    }
+
+    d, err := Open(filter, dictionaryPath)
+    if err != nil {
+        return nil, err
+    }
+    loaded[key] = d
+
+    return d, nil
+}
+
+
+// Open reads the word lists at the given paths into a single dictionary.
+// Words are normalised by the filter, and the counts of words appearing
+// several times are added together before the filter decides which words
+// are kept.
+func Open(filter Filter, paths ...string) (*Dictionary, error) {
+    wf, err := filter.compile()
+    if err != nil {
+        return nil, err
+    }
+
+    d := &Dictionary{ Paths: paths, Filter: filter }
+
+    positions := make(map[string]int)
+    for _, dictionaryPath := range paths {
+        if err := d.read(dictionaryPath, wf, positions); err != nil {
+            return nil, err
+        }
+    }
+
+    d.applyFilter(wf)
+    d.buildIndex()
+
+    return d, nil
+}
+
+
+// read adds the normalised words of the word list at the given path.
+func (d *Dictionary) read(dictionaryPath string, wf *wordFilter, positions map[string]int) error {
+    f, err := os.Open(dictionaryPath)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    scanner := bufio.NewScanner(f)
+    for line := 1; scanner.Scan(); line++ {
+        word, count, err := parseLine(scanner.Text())
+        if err != nil {
+            return fmt.Errorf("%s:%d: %w", dictionaryPath, line, err)
+        }
+
+        word = wf.normalize(word)
+        if word == "" {
+            continue
+        }
+
+        if i, ok := positions[word]; ok {
+            d.counts[i] += count
+            continue
+        }
+
+        positions[word] = len(d.words)
+        d.words = append(d.words, word)
+        d.counts = append(d.counts, count)
+    }
+
+    return scanner.Err()
+}
+
+
+// applyFilter removes the words which do not pass the filter.
+func (d *Dictionary) applyFilter(wf *wordFilter) {
+    kept := 0
+    for i, word := range d.words {
+        if wf.keep(word, d.counts[i]) {
+            d.words[kept] = word
+            d.counts[kept] = d.counts[i]
+            kept++
+        }
+    }
+
+    d.words = d.words[:kept]
+    d.counts = d.counts[:kept]
+}
+
+
+// buildIndex sorts the characters by frequency and groups the words by their
+// length and character set.
+func (d *Dictionary) buildIndex() {
+    occurences := make(map[rune]int)
+    for i, word := range d.words {
+        for _, char := range word {
+            occurences[char] += d.counts[i]
+        }
+    }
+
+    d.priority = make([]rune, 0, len(occurences))
+    for char := range occurences {
+        d.priority = append(d.priority, char)
+    }
+
+    // Ties are broken by the character so the order is the same every time
+    sort.Slice(d.priority, func(i, j int) bool {
+        if occurences[d.priority[i]] != occurences[d.priority[j]] {
+            return occurences[d.priority[i]] > occurences[d.priority[j]]
+        }
+        return d.priority[i] < d.priority[j]
+    })
+
+    // The most frequent characters get their own bit
+    d.bits = make(map[rune]uint, len(d.priority))
+    for i, char := range d.priority {
+        if i < overflowBit {
+            d.bits[char] = uint(i)
+        } else {
+            d.bits[char] = overflowBit
+        }
+    }
+
+    groups := make(map[int]map[uint64]int)
+    d.index = make(map[int][]wordGroup)
+    for i, word := range d.words {
+        length := utf8.RuneCountInString(word)
+        mask := d.mask([]rune(word))
+
+        if groups[length] == nil {
+            groups[length] = make(map[uint64]int)
+        }
+        g, ok := groups[length][mask]
+        if !ok {
+            g = len(d.index[length])
+            groups[length][mask] = g
+            d.index[length] = append(d.index[length], wordGroup{ mask: mask })
+        }
+        d.index[length][g].words = append(d.index[length][g].words, i)
+    }
+}
+
+
+// mask returns the character set mask of the characters. Characters which
+// are not in the dictionary are left out.
+func (d *Dictionary) mask(chars []rune) uint64 {
+    mask := uint64(0)
+    for _, char := range chars {
+        if bit, ok := d.bits[char]; ok {
+            mask |= 1 << bit
+        }
+    }
+
+    return mask
+}
+
+
+// CharacterPriority returns the characters of the dictionary sorted by how
+// often they occur, weighted by the count of the words they appear in. Equally
+// frequent characters are ordered by their code point.
+func (d *Dictionary) CharacterPriority() []rune {
+    priority := make([]rune, len(d.priority))
+    copy(priority, d.priority)
+
+    return priority
+}
+
+
+// WordCounts returns how often each word of the dictionary occurs.
+func (d *Dictionary) WordCounts() map[string]int {
+    counts := make(map[string]int, len(d.words))
+    for i, word := range d.words {
+        counts[word] = d.counts[i]
+    }
+
+    return counts
+}
+
+
+// lookup returns the indices of the words which only use the characters in
+// "chars" and satisfy the min and max length, in ascending order.
+func (d *Dictionary) lookup(chars []rune, minLength, maxLength int) []int {
+    allowed := d.mask(chars)
+    charsSet := make(map[rune]bool, len(chars))
+    for _, char := range chars {
+        charsSet[char] = true
+    }
+
+    indices := make([]int, 0)
+    for length := minLength; length <= maxLength; length++ {
+        for _, group := range d.index[length] {
+            if group.mask &^ allowed != 0 {
+                continue
+            }
+
+            if group.mask & (1 << overflowBit) == 0 {
+                indices = append(indices, group.words...)
+                continue
+            }
+
+            // The overflow bit is shared, so the rare characters are checked one by one
+            for _, i := range group.words {
+                if usesOnly(d.words[i], charsSet) {
+                    indices = append(indices, i)
+                }
+            }
+        }
+    }
+    sort.Ints(indices)
+
+    return indices
+}
+
+
+// WordsFromTargets gets "amount" of words from the dictionary which use only the characters in "chars", which
+// contain at least one of the target characters and satisfy the min and max length. Each candidate is scored by
+// the sum of the weights of the distinct target characters it contains, and words are picked with a probability
+// proportional to their score, so words containing more of the targets are preferred. If fewer than four words
+// qualify, random words are generated to fill in. All randomness is drawn from rng, so the same source gives
+// the same words.
+func (d *Dictionary) WordsFromTargets(rng *rand.Rand, chars []rune, targets map[rune]float64, minLength, maxLength, amount int) []string {
+    if len(targets) == 0 {
+        return nil
+    }
+
+    charsSet := make(map[rune]bool)
+    for _, char := range chars {
+        charsSet[char] = true
+    }
+
+    words := make([]string, 0)
+    scores := make([]float64, 0)
+    for _, i := range d.lookup(chars, minLength, maxLength) {
+        if score, ok := scoreWord(d.words[i], charsSet, targets); ok {
+            words = append(words, d.words[i])
+            scores = append(scores, score)
+        }
+    }
+
+    targetChars := mapKeys(targets)
+    sort.Slice(targetChars, func(i, j int) bool {
+        return targetChars[i] < targetChars[j]
+    })
+
+    targetWeights := make([]float64, len(targetChars))
+    for i, char := range targetChars {
+        targetWeights[i] = targets[char]
+    }
+
+    for len(words) < 4 {
+        targetChar := targetChars[pickWeighted(rng, targetWeights)]
+        word := GenerateWord(rng, chars, targetChar, minLength, maxLength)
+        score, _ := scoreWord(word, charsSet, targets)
+
+        words = append(words, word)
+        scores = append(scores, score)
+    }
+
+    selectedWords := make([]string, amount)
+    for i := 0; i < amount; i++ {
+        selectedWords[i] = words[pickWeighted(rng, scores)]
+    }
+
+    return selectedWords
+}
+
+
+// usesOnly returns true if every character of the word is in charsSet.
+func usesOnly(word string, charsSet map[rune]bool) bool {
+    for _, char := range word {
+        if !charsSet[char] {
+            return false
+        }
+    }
+
+    return true
+}
+
+
+// mapKeys returns the characters of the map in no particular order.
+func mapKeys(m map[rune]float64) []rune {
+    keys := make([]rune, 0, len(m))
+    for char := range m {
+        keys = append(keys, char)
+    }
+
+    return keys
+}
+
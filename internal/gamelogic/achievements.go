@@ -0,0 +1,240 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+	"github.com/gdamore/tcell/v2"
+)
+
+// letterPrefix starts the IDs of the achievements for unlocking a letter,
+// which are followed by the letter.
+const letterPrefix = "letter:"
+
+
+// achievement is a milestone the player earns once, checked after every
+// lesson.
+type achievement struct {
+    id          string
+    name        string
+    description string
+    earned      func(record shared.SessionRecord) bool     // If the lesson of the record earns the achievement
+}
+
+
+// achievementList is the order the achievements are shown in.
+var achievementList = []achievement{
+    {
+        id: "wpm40",
+        name: "Up to speed",
+        description: "Finish a lesson at 40 WPM or more",
+        earned: func(record shared.SessionRecord) bool {
+            return record.CPM / 5 >= 40
+        },
+    },
+    {
+        id: "flawless",
+        name: "Flawless",
+        description: "Finish a lesson with 100% accuracy",
+        earned: func(record shared.SessionRecord) bool {
+            return record.Correct > 0 && record.Incorrect == 0
+        },
+    },
+    {
+        id: "homeRow",
+        name: "Home sweet home",
+        description: "Score above 0.9 on every home row key of your layout",
+        earned: func(record shared.SessionRecord) bool {
+            return homeRowMastered()
+        },
+    },
+    {
+        id: "streak7",
+        name: "Week of practice",
+        description: "Meet your daily goals 7 days in a row",
+        earned: func(record shared.SessionRecord) bool {
+            return currentStreak(time.UnixMilli(record.Time)) >= 7
+        },
+    },
+}
+
+
+// achievements stores when each earned achievement was earned in
+// milliseconds since unix, by ID.
+var achievements map[string]int64
+
+
+// loadAchievements loads the earned achievements from the achievements
+// file. If no file exists no achievements have been earned.
+func loadAchievements() error {
+    achievements = make(map[string]int64)
+    if _, err := os.Stat(savePath("achievements")); errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+
+    saveData, err := os.ReadFile(savePath("achievements"))
+    if err != nil {
+        return err
+    }
+
+    return json.Unmarshal(saveData, &achievements)
+}
+
+
+// saveAchievements writes the earned achievements to the achievements file.
+func saveAchievements() error {
+    b, err := json.Marshal(achievements)
+    if err != nil {
+        return err
+    }
+
+    return os.WriteFile(savePath("achievements"), b, 0644)
+}
+
+
+// recordAchievements checks the achievements not earned yet against the
+// lesson of the record, which must already be in the history, and saves
+// the ones it earned. Every letter newly unlocked earns an achievement of its
+// own, though letters unlocked by the same lesson are returned as one.
+// Returns the achievements earned by the lesson.
+func recordAchievements(record shared.SessionRecord) ([]graphics.Achievement, error) {
+    var earned []graphics.Achievement
+    for _, a := range achievementList {
+        if _, ok := achievements[a.id]; ok || !a.earned(record) {
+            continue
+        }
+
+        achievements[a.id] = record.Time
+        earned = append(earned, graphics.Achievement{ Name: a.name, Description: a.description, Earned: record.Time })
+    }
+
+    letters, baseline := unlockLetters(record.Time)
+    if len(letters) > 0 {
+        earned = append(earned, lettersAchievement(letters, record.Time))
+    }
+
+    if len(earned) == 0 && !baseline {
+        return nil, nil
+    }
+
+    return earned, saveAchievements()
+}
+
+
+// unlockLetters marks the letters of the unlocked characters as unlocked at
+// the given time and returns the ones which were not unlocked before. The
+// unlocked characters are the current characters when they are the first
+// characters of the unlock order, so lessons of courses do not unlock any.
+// The letters the player starts out with are marked without being returned,
+// in which case baseline is true.
+func unlockLetters(at int64) (letters []rune, baseline bool) {
+    unlocked := gameCtx.CurrentChars
+    if len(unlocked) > len(gameCtx.CharacterPriorities) || string(gameCtx.CharacterPriorities[:len(unlocked)]) != string(unlocked) {
+        return nil, false
+    }
+
+    baseline = true
+    for id := range achievements {
+        if strings.HasPrefix(id, letterPrefix) {
+            baseline = false
+            break
+        }
+    }
+
+    for _, char := range unlocked {
+        id := letterPrefix + string(char)
+        if _, ok := achievements[id]; ok || !unicode.IsLetter(char) {
+            continue
+        }
+
+        achievements[id] = at
+        if !baseline {
+            letters = append(letters, char)
+        }
+    }
+
+    return letters, baseline
+}
+
+
+// lettersAchievement returns the achievement for unlocking the given
+// letters.
+func lettersAchievement(letters []rune, earned int64) graphics.Achievement {
+    names := make([]string, len(letters))
+    for i, letter := range letters {
+        names[i] = string(letter)
+    }
+
+    name := "New letter"
+    if len(letters) > 1 {
+        name = "New letters"
+    }
+
+    return graphics.Achievement{
+        Name: name,
+        Description: "Unlock " + strings.Join(names, ", "),
+        Earned: earned,
+    }
+}
+
+
+// homeRowMastered returns if every character on the home row of the layout
+// which is in the dictionary scores above 0.9.
+func homeRowMastered() bool {
+    homeRow := gameCtx.Layout.Rows[gameCtx.Layout.HomeRow]
+
+    keys := 0
+    for _, char := range gameCtx.CharacterPriorities {
+        if !strings.ContainsRune(homeRow, char) {
+            continue
+        }
+
+        keys++
+        if ca, ok := gameCtx.CharacterAccuracies[char]; !ok || ca.Score <= 0.9 {
+            return false
+        }
+    }
+
+    return keys > 0
+}
+
+
+// showAchievements shows every achievement, earned or not, and the letters
+// unlocked so far in their unlock order.
+func showAchievements() {
+    list := make([]graphics.Achievement, len(achievementList))
+    for i, a := range achievementList {
+        list[i] = graphics.Achievement{ Name: a.name, Description: a.description, Earned: achievements[a.id] }
+    }
+
+    var letters []rune
+    for _, char := range gameCtx.CharacterPriorities {
+        if _, ok := achievements[letterPrefix + string(char)]; ok {
+            letters = append(letters, char)
+        }
+    }
+
+    graphicsCtx.ShowAchievements(list, letters, len(gameCtx.CharacterPriorities))
+}
+
+
+// achievementsInputHandler handles the input on the achievements screen.
+// <Enter> returns to the end screen.
+func achievementsInputHandler(event *tcell.EventKey) *tcell.EventKey {
+    if event.Key() == tcell.KeyEnter {
+        showEndScreen()
+        inputCaptureChangeChan <- endScreenInputHandler
+        return nil
+    } else if event.Key() == tcell.KeyEscape {
+        graphicsCtx.App.Stop()
+        return nil
+    }
+
+    return event
+}
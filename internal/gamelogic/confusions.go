@@ -0,0 +1,169 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
+	"github.com/gdamore/tcell/v2"
+)
+
+// confusionsShown is the amount of substitutions listed on the confusions
+// screen.
+const confusionsShown = 15
+
+
+// ConfusionMatrix counts how often each character was typed in place of
+// each expected character, by expected and then by typed character.
+type ConfusionMatrix map[rune]map[rune]int
+
+
+var confusions ConfusionMatrix
+
+
+// loadConfusions loads the confusion matrix from the confusions file. If no
+// file exists an empty matrix is used.
+func loadConfusions() error {
+    confusions = make(ConfusionMatrix)
+    if _, err := os.Stat(savePath("confusions")); errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+
+    saveData, err := os.ReadFile(savePath("confusions"))
+    if err != nil {
+        return err
+    }
+
+    return json.Unmarshal(saveData, &confusions)
+}
+
+
+// saveConfusions writes the confusion matrix to the confusions file.
+func saveConfusions() error {
+    b, err := json.Marshal(confusions)
+    if err != nil {
+        return err
+    }
+
+    return os.WriteFile(savePath("confusions"), b, 0644)
+}
+
+
+// deleteConfusions removes the confusions file and clears the matrix.
+func deleteConfusions() error {
+    confusions = make(ConfusionMatrix)
+    if err := os.Remove(savePath("confusions")); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return err
+    }
+
+    return nil
+}
+
+
+// recordConfusion counts the typed character being typed in place of the
+// expected one.
+func recordConfusion(expected, typed rune) {
+    if confusions[expected] == nil {
+        confusions[expected] = make(map[rune]int)
+    }
+    confusions[expected][typed]++
+}
+
+
+// substitutions returns the substitutions in the confusion matrix, most
+// common first and ties by the expected and then the typed character. The
+// share of each is taken of every error made on its expected character.
+func substitutions(matrix ConfusionMatrix) []graphics.Confusion {
+    var list []graphics.Confusion
+    for expected, typed := range matrix {
+        total := 0
+        for _, count := range typed {
+            total += count
+        }
+
+        for typedChar, count := range typed {
+            list = append(list, graphics.Confusion{
+                Expected: expected,
+                Typed: typedChar,
+                Count: count,
+                Share: float64(count) / float64(total),
+            })
+        }
+    }
+
+    sort.Slice(list, func(i, j int) bool {
+        if list[i].Count != list[j].Count {
+            return list[i].Count > list[j].Count
+        }
+        if list[i].Expected != list[j].Expected {
+            return list[i].Expected < list[j].Expected
+        }
+        return list[i].Typed < list[j].Typed
+    })
+
+    return list
+}
+
+
+// interferingLayouts returns the built-in layouts, other than the one in
+// use, which have the typed character on the key of the expected character
+// in the layout in use. Such substitutions hint at muscle memory from those
+// layouts.
+func interferingLayouts(current *layout.Layout, expected, typed rune) []string {
+    key, ok := current.Key(expected)
+    if !ok || expected == ' ' {
+        return nil
+    }
+
+    var names []string
+    for _, name := range layout.BuiltinNames() {
+        if name == current.Name {
+            continue
+        }
+
+        other, err := layout.Load(name)
+        if err != nil {
+            continue
+        }
+
+        if char, ok := other.CharAt(key.Row, key.Column); ok && char == typed {
+            names = append(names, name)
+        }
+    }
+
+    return names
+}
+
+
+// showConfusions shows the most common substitutions of the profile.
+func showConfusions() {
+    list := substitutions(confusions)
+    if len(list) > confusionsShown {
+        list = list[:confusionsShown]
+    }
+
+    for i := range list {
+        list[i].Layouts = interferingLayouts(gameCtx.Layout, list[i].Expected, list[i].Typed)
+    }
+
+    graphicsCtx.ShowConfusions(gameCtx.Layout.Name, list)
+}
+
+
+// confusionsInputHandler handles the input on the confusions screen.
+// <Enter> returns to the end screen.
+func confusionsInputHandler(event *tcell.EventKey) *tcell.EventKey {
+    if event.Key() == tcell.KeyEnter {
+        showEndScreen()
+        inputCaptureChangeChan <- endScreenInputHandler
+        return nil
+    } else if event.Key() == tcell.KeyEscape {
+        graphicsCtx.App.Stop()
+        return nil
+    }
+
+    return event
+}
@@ -0,0 +1,90 @@
+package gamelogic
+
+// The error correction policies selectable in GameSettings.ErrorPolicy.
+const (
+    PolicyFree      = "free"        // The cursor moves on after errors, and backspace may be used to correct them
+    PolicyStop      = "stop"        // The cursor stays on a character until the right key is pressed, and backspace is disabled
+    PolicyStrict    = "strict"      // The cursor moves on after errors, and backspace is disabled
+)
+
+
+// charProgress is how a character of a lesson has been typed.
+type charProgress struct {
+    Typed       bool        // If the character has been typed at all
+    FirstTry    bool        // If the first keystroke on the character was correct
+    Correct     bool        // If the latest keystroke on the character was correct
+    Passed      int64       // The time the cursor last moved past the character in milliseconds since the lesson started
+}
+
+
+// correctionTracker applies the error correction policy to a lesson and
+// tracks which characters were typed correctly on the first try. Only the
+// first keystroke on each character counts towards the accuracy of the
+// character, later keystrokes are corrections.
+type correctionTracker struct {
+    policy      string
+    chars       []charProgress
+}
+
+
+// newCorrectionTracker returns a tracker for a lesson of the given length
+// using the given policy.
+func newCorrectionTracker(policy string, length int) correctionTracker {
+    return correctionTracker{ policy: policy, chars: make([]charProgress, length) }
+}
+
+
+// keystroke records a keystroke on the character at the index. Returns if it
+// was the first keystroke on the character, and if the cursor should move on
+// to the next character.
+func (t *correctionTracker) keystroke(index int, correct bool) (bool, bool) {
+    char := &t.chars[index]
+    firstTry := !char.Typed
+
+    char.Correct = correct
+    if firstTry {
+        char.Typed = true
+        char.FirstTry = correct
+    }
+
+    return firstTry, correct || t.policy != PolicyStop
+}
+
+
+// pass records the time in milliseconds since the lesson started at which
+// the cursor moved past the character at the index.
+func (t *correctionTracker) pass(index int, at int64) {
+    t.chars[index].Passed = at
+}
+
+
+// mistyped returns true if the character at the index has been typed wrong
+// on the first try, so that typing it correctly is a correction.
+func (t *correctionTracker) mistyped(index int) bool {
+    char := t.chars[index]
+    return char.Typed && !char.FirstTry
+}
+
+
+// canBackspace returns true if the policy allows moving back to correct
+// errors. When the cursor stops on errors there is nothing to correct.
+func (t *correctionTracker) canBackspace() bool {
+    return t.policy == PolicyFree
+}
+
+
+// stats returns the amount of characters typed wrong on the first try and
+// how many of those were corrected afterwards.
+func (t *correctionTracker) stats() (int, int) {
+    firstTryErrors, corrected := 0, 0
+    for _, char := range t.chars {
+        if char.Typed && !char.FirstTry {
+            firstTryErrors++
+            if char.Correct {
+                corrected++
+            }
+        }
+    }
+
+    return firstTryErrors, corrected
+}
@@ -0,0 +1,108 @@
+package gamelogic
+
+import (
+	"testing"
+)
+
+func TestCorrectionTracker(t *testing.T) {
+    tests := []struct {
+        name            string
+        policy          string
+        keystrokes      []bool      // If each keystroke on the first character was correct
+        wantAdvance     []bool      // If the cursor moves on after each keystroke
+        wantMistyped    bool
+        wantErrors      int
+        wantCorrected   int
+    }{
+        {
+            name: "correct on the first try",
+            policy: PolicyFree,
+            keystrokes: []bool{ true },
+            wantAdvance: []bool{ true },
+        },
+        {
+            name: "error moving on",
+            policy: PolicyFree,
+            keystrokes: []bool{ false },
+            wantAdvance: []bool{ true },
+            wantMistyped: true,
+            wantErrors: 1,
+        },
+        {
+            name: "error corrected",
+            policy: PolicyFree,
+            keystrokes: []bool{ false, true },
+            wantAdvance: []bool{ true, true },
+            wantMistyped: true,
+            wantErrors: 1,
+            wantCorrected: 1,
+        },
+        {
+            name: "correct character retyped wrong",
+            policy: PolicyFree,
+            keystrokes: []bool{ true, false },
+            wantAdvance: []bool{ true, true },
+        },
+        {
+            name: "stopping on errors",
+            policy: PolicyStop,
+            keystrokes: []bool{ false, false, true },
+            wantAdvance: []bool{ false, false, true },
+            wantMistyped: true,
+            wantErrors: 1,
+            wantCorrected: 1,
+        },
+        {
+            name: "strict error",
+            policy: PolicyStrict,
+            keystrokes: []bool{ false },
+            wantAdvance: []bool{ true },
+            wantMistyped: true,
+            wantErrors: 1,
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            tracker := newCorrectionTracker(test.policy, 2)
+            for i, correct := range test.keystrokes {
+                firstTry, advance := tracker.keystroke(0, correct)
+                if firstTry != (i == 0) {
+                    t.Errorf("keystroke %d first try = %t, want %t", i, firstTry, i == 0)
+                }
+                if advance != test.wantAdvance[i] {
+                    t.Errorf("keystroke %d advance = %t, want %t", i, advance, test.wantAdvance[i])
+                }
+            }
+
+            if got := tracker.mistyped(0); got != test.wantMistyped {
+                t.Errorf("mistyped(0) = %t, want %t", got, test.wantMistyped)
+            }
+            if tracker.mistyped(1) {
+                t.Error("mistyped(1) = true for an untyped character, want false")
+            }
+            if errors, corrected := tracker.stats(); errors != test.wantErrors || corrected != test.wantCorrected {
+                t.Errorf("stats() = %d, %d, want %d, %d", errors, corrected, test.wantErrors, test.wantCorrected)
+            }
+        })
+    }
+}
+
+
+func TestCanBackspace(t *testing.T) {
+    tests := []struct {
+        policy      string
+        want        bool
+    }{
+        { policy: PolicyFree, want: true },
+        { policy: PolicyStop, want: false },
+        { policy: PolicyStrict, want: false },
+    }
+
+    for _, test := range tests {
+        tracker := newCorrectionTracker(test.policy, 1)
+        if got := tracker.canBackspace(); got != test.want {
+            t.Errorf("canBackspace() with policy %q = %t, want %t", test.policy, got, test.want)
+        }
+    }
+}
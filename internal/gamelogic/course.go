@@ -0,0 +1,146 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+)
+
+// courseProgress stores the index of the stage the player is on in each
+// course, by the name of the course. A course is completed when the index
+// is past its last stage.
+var courseProgress map[string]int
+
+
+// loadCourseProgress loads the course progress from the course file. If no
+// file exists every course starts at its first stage.
+func loadCourseProgress() error {
+    courseProgress = make(map[string]int)
+    if _, err := os.Stat(savePath("course")); errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+
+    saveData, err := os.ReadFile(savePath("course"))
+    if err != nil {
+        return err
+    }
+
+    return json.Unmarshal(saveData, &courseProgress)
+}
+
+
+// saveCourseProgress writes the course progress to the course file.
+func saveCourseProgress() error {
+    b, err := json.Marshal(courseProgress)
+    if err != nil {
+        return err
+    }
+
+    return os.WriteFile(savePath("course"), b, 0644)
+}
+
+
+// deleteCourseProgress removes the course file, so that every course starts
+// at its first stage again.
+func deleteCourseProgress() error {
+    courseProgress = make(map[string]int)
+    if err := os.Remove(savePath("course")); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return err
+    }
+
+    return nil
+}
+
+
+// courseStage returns the index of the stage the player is on in the course
+// set in the settings. Returns false if there is no course, if it has been
+// completed or during races.
+func courseStage() (int, bool) {
+    if gameCtx.Course == nil || gameCtx.Race != nil {
+        return 0, false
+    }
+
+    stage := courseProgress[gameCtx.Course.Name]
+
+    return stage, stage < len(gameCtx.Course.Stages)
+}
+
+
+// generateStageWords generates the words of a lesson of the given stage of
+// the course from the seed. Stages with a fixed text always use the text,
+// otherwise the given amount of words are made of the characters of the
+// stage and target the weakest of them.
+func generateStageWords(stage int, seed int64, minWordLength, maxWordLength, wordCount int) string {
+    s := gameCtx.Course.Stages[stage]
+
+    gameCtx.CourseStage = stage
+    gameCtx.CurrentChars = []rune(s.Characters)
+    gameCtx.PriorityCharacters = getPriorityCharacters(gameCtx.Settings.PriorityCount)
+    gameCtx.Seed = seed
+
+    if s.Text != "" {
+        setSource(LessonText, 0, 0)
+        return joinWords(strings.Fields(s.Text))
+    }
+    setSource("", minWordLength, maxWordLength)
+
+    wordsList := gameCtx.Dictionary.WordsFromTargets(
+        rand.New(rand.NewSource(seed)),
+        gameCtx.CurrentChars,
+        priorityWeights(gameCtx.PriorityCharacters),
+        minWordLength,
+        maxWordLength,
+        wordCount,
+    )
+
+    return joinWords(wordsList)
+}
+
+
+// recordCourseLesson moves on to the next stage of the course if the lesson
+// just finished was of the current stage and passed it at the given words
+// per minute and accuracy. Returns the result of the lesson in the course,
+// nil if it was not part of it.
+func recordCourseLesson(wpm, accuracy float64) (*graphics.CourseResult, error) {
+    current, ok := courseStage()
+    if !ok || gameCtx.CourseStage != current {
+        return nil, nil
+    }
+
+    s := gameCtx.Course.Stages[current]
+    result := graphics.CourseResult{
+        Name: gameCtx.Course.Name,
+        Stage: current,
+        Stages: len(gameCtx.Course.Stages),
+        StageName: s.Name,
+        Passed: s.Passed(wpm, accuracy),
+        TargetWPM: s.WPM,
+        TargetAccuracy: s.Accuracy,
+    }
+
+    if !result.Passed {
+        return &result, nil
+    }
+
+    courseProgress[gameCtx.Course.Name] = current + 1
+    if next, ok := courseStage(); ok {
+        result.NextStageName = gameCtx.Course.Stages[next].Name
+    }
+
+    return &result, saveCourseProgress()
+}
+
+
+// courseStageName returns the name of the course stage of the current
+// lesson, empty if it is not part of the course.
+func courseStageName() string {
+    if gameCtx.CourseStage < 0 {
+        return ""
+    }
+
+    return gameCtx.Course.Stages[gameCtx.CourseStage].Name
+}
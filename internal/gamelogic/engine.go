@@ -0,0 +1,419 @@
+package gamelogic
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"unicode/utf8"
+
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// LessonParams are the parameters for generating a lesson without the TUI.
+// Fields left at their zero value are taken from the game settings.
+type LessonParams struct {
+    NumChars            int         `json:"numChars"`           // The number of characters to use from the character priorities
+    MinWordLength       int         `json:"minWordLength"`      // The min word length (inclusive)
+    MaxWordLength       int         `json:"maxWordLength"`      // The max word length (inclusive)
+    WordCount           int         `json:"wordCount"`          // The amount of words to include in the lesson
+    PriorityCount       int         `json:"priorityCount"`      // The amount of priority characters to target
+    PriorityCharacters  string      `json:"priorityCharacters"` // The characters to target, chosen by the scheduler if empty
+    Seed                int64       `json:"seed"`               // The seed to generate the words from, the next seed of the game if 0
+    Type                string      `json:"type"`               // The type of lesson, LessonProblemWords or empty for a regular lesson
+}
+
+// Lesson is a lesson generated without the TUI.
+type Lesson struct {
+    Words               string      `json:"words"`              // The words of the lesson, each followed by a space
+    Characters          string      `json:"characters"`         // The characters used in the lesson
+    PriorityCharacters  string      `json:"priorityCharacters"` // The characters targeted by the words, weakest first
+    Seed                int64       `json:"seed"`               // The seed the words were generated from
+    CourseStage         string      `json:"courseStage,omitempty"` // The name of the course stage the lesson is part of, empty if none
+}
+
+// Keystroke is a single keystroke made by the player in a lesson played
+// without the TUI.
+type Keystroke struct {
+    Expected            string      `json:"expected"`           // The character the player should have typed
+    Typed               string      `json:"typed"`              // The character the player typed
+    Time                int64       `json:"time"`               // The time since the previous keystroke in milliseconds
+}
+
+// LessonResults are the keystrokes made by the player in a lesson played
+// without the TUI.
+type LessonResults struct {
+    Words               string      `json:"words"`              // The words of the lesson
+    Seed                int64       `json:"seed"`               // The seed the words were generated from, 0 if unknown
+    Keystrokes          []Keystroke `json:"keystrokes"`         // The keystrokes in the order they were made
+}
+
+
+// LoadGame creates a fresh game context from the dictionary and the saved
+// progress of the player without starting the TUI.
+func LoadGame() error {
+    return initGame()
+}
+
+
+// GenerateLesson generates a new lesson given the parameters. While a course
+// is followed, regular lessons which do not ask for specific characters are
+// lessons of the current course stage.
+func GenerateLesson(params LessonParams) (Lesson, error) {
+    followCourse := params.Type == "" && params.NumChars == 0 && params.PriorityCharacters == ""
+    if params.NumChars == 0 {
+        params.NumChars = gameCtx.Settings.NumChars
+    }
+    if params.MinWordLength == 0 {
+        params.MinWordLength = gameCtx.Settings.MinWordLength
+    }
+    if params.MaxWordLength == 0 {
+        params.MaxWordLength = gameCtx.Settings.MaxWordLength
+    }
+    if params.WordCount == 0 {
+        params.WordCount = gameCtx.Settings.WordCount
+    }
+    if params.PriorityCount == 0 {
+        params.PriorityCount = gameCtx.Settings.PriorityCount
+    }
+
+    if params.NumChars < 1 || params.NumChars > len(gameCtx.CharacterPriorities) {
+        return Lesson{}, fmt.Errorf("numChars must be between 1 and %d", len(gameCtx.CharacterPriorities))
+    }
+    if params.MinWordLength < 1 || params.MinWordLength > params.MaxWordLength {
+        return Lesson{}, errors.New("minWordLength must be positive and at most maxWordLength")
+    }
+    if params.WordCount < 1 {
+        return Lesson{}, errors.New("wordCount must be positive")
+    }
+    if params.PriorityCount < 1 {
+        return Lesson{}, errors.New("priorityCount must be positive")
+    }
+
+    seed := params.Seed
+    if seed == 0 {
+        seed = nextSeed()
+    }
+
+    gameCtx.CourseStage = -1
+    if stage, ok := courseStage(); ok && followCourse {
+        words := generateStageWords(stage, seed, params.MinWordLength, params.MaxWordLength, params.WordCount)
+        gameCtx.Words = words
+        initCharacterAccuracies()
+
+        return Lesson{
+            Words: words,
+            Characters: string(gameCtx.CurrentChars),
+            PriorityCharacters: string(gameCtx.PriorityCharacters),
+            Seed: seed,
+            CourseStage: courseStageName(),
+        }, nil
+    }
+
+    gameCtx.CurrentChars = gameCtx.CharacterPriorities[:params.NumChars]
+    initCharacterAccuracies()
+
+    if params.PriorityCharacters == "" {
+        gameCtx.PriorityCharacters = getPriorityCharacters(params.PriorityCount)
+    } else {
+        gameCtx.PriorityCharacters = []rune(params.PriorityCharacters)
+    }
+
+    gameCtx.Seed = seed
+    setSource(params.Type, params.MinWordLength, params.MaxWordLength)
+
+    var words string
+    switch params.Type {
+    case "":
+        wordsList := gameCtx.Dictionary.WordsFromTargets(
+            rand.New(rand.NewSource(seed)),
+            gameCtx.CurrentChars,
+            priorityWeights(gameCtx.PriorityCharacters),
+            params.MinWordLength,
+            params.MaxWordLength,
+            params.WordCount,
+        )
+        words = joinWords(wordsList)
+    case LessonProblemWords:
+        var err error
+        words, err = generateProblemWords(seed, params.WordCount)
+        if err != nil {
+            return Lesson{}, err
+        }
+    default:
+        return Lesson{}, fmt.Errorf("unknown lesson type %q", params.Type)
+    }
+    gameCtx.Words = words
+
+    return Lesson{
+        Words: words,
+        Characters: string(gameCtx.CurrentChars),
+        PriorityCharacters: string(gameCtx.PriorityCharacters),
+        Seed: seed,
+    }, nil
+}
+
+
+// SubmitResults scores the keystrokes of a finished lesson the same way as
+// when playing in the TUI, then saves the accuracies and adds the lesson to
+// the history. The keystrokes must match the characters of the words. The
+// seed is only recorded if the words are the ones last generated from it,
+// otherwise the lesson is recorded with an unknown seed.
+func SubmitResults(results LessonResults) (shared.SessionRecord, error) {
+    if len(results.Keystrokes) == 0 {
+        return shared.SessionRecord{}, errors.New("no keystrokes were submitted")
+    }
+
+    // Every character of the words but the last space is typed once
+    words := []rune(results.Words)
+    if len(words) - 1 != len(results.Keystrokes) {
+        return shared.SessionRecord{}, fmt.Errorf("expected a keystroke for each of the %d characters of the words, got %d", len(words) - 1, len(results.Keystrokes))
+    }
+
+    // Validate every keystroke before recording any of them so that a bad
+    // submission does not leave the accuracies half updated
+    expected := make([]rune, len(results.Keystrokes))
+    for i, keystroke := range results.Keystrokes {
+        char, err := parseCharacter(keystroke.Expected)
+        if err != nil {
+            return shared.SessionRecord{}, fmt.Errorf("keystroke %d: %w", i, err)
+        }
+        if char != words[i] {
+            return shared.SessionRecord{}, fmt.Errorf("keystroke %d: expected %q but the words have %q", i, char, words[i])
+        }
+        expected[i] = char
+    }
+
+    // Characters never attempted start out like the ones of a lesson in the
+    // TUI do
+    for _, char := range expected {
+        initCharacterAccuracy(char)
+    }
+
+    // The submitted keystrokes are made on consecutive characters, so every
+    // keystroke is the first on its character
+    tally := newLessonTally(PolicyFree, len(results.Keystrokes))
+    for i, keystroke := range results.Keystrokes {
+        typed, _ := utf8.DecodeRuneInString(keystroke.Typed)
+        tally.record(i, expected[i], typed, keystroke.Time)
+    }
+
+    seed := results.Seed
+    if seed != gameCtx.Seed || results.Words != gameCtx.Words {
+        seed = 0
+    }
+
+    return tally.finish(results.Words, seed)
+}
+
+
+// LessonSession is a lesson played one keystroke at a time without the TUI
+// following the error policy in the settings.
+type LessonSession struct {
+    Words       string      // The words of the lesson
+    chars       []rune      // The characters of the words, which Index refers to
+    Seed        int64       // The seed the words were generated from
+    Index       int         // The index of the character currently in play
+    Finished    bool        // Finished becomes true when the end of the words is reached
+    tally       lessonTally
+}
+
+
+// NewLessonSession starts playing the given lesson.
+func NewLessonSession(lesson Lesson) *LessonSession {
+    return &LessonSession{
+        Words: lesson.Words,
+        Seed: lesson.Seed,
+        chars: []rune(lesson.Words),
+        tally: newLessonTally(gameCtx.Settings.ErrorPolicy, utf8.RuneCountInString(lesson.Words)),
+    }
+}
+
+
+// Keystroke scores a keystroke on the character currently in play the same
+// way as when playing in the TUI and moves on to the next character unless
+// the error policy keeps the cursor on it. The time since the previous
+// keystroke is given in milliseconds. When the end of the words is reached
+// the lesson is saved and its record is returned.
+func (s *LessonSession) Keystroke(typed rune, elapsed int64) (bool, *shared.SessionRecord, error) {
+    if s.Finished {
+        return false, nil, errors.New("the lesson is already finished")
+    }
+
+    expected := s.chars[s.Index]
+    success, advance := s.tally.record(s.Index, expected, typed, elapsed)
+    if !advance {
+        return success, nil, nil
+    }
+
+    s.Index++
+    if s.Index < len(s.chars) - 1 {
+        return success, nil, nil
+    }
+
+    s.Finished = true
+    record, err := s.tally.finish(s.Words, s.Seed)
+    if err != nil {
+        return success, nil, err
+    }
+
+    return success, &record, nil
+}
+
+
+// Backspace moves back to the previous character unless the error policy
+// disables backspace. The accuracy already recorded for it is kept, and
+// typing it again counts as a correction, as in the TUI.
+func (s *LessonSession) Backspace() {
+    if s.Index > 0 && !s.Finished && s.tally.corrections.canBackspace() {
+        s.Index--
+    }
+}
+
+
+// FirstTry returns true if the first keystroke on the character at the
+// index was correct, or if it has not been typed.
+func (s *LessonSession) FirstTry(index int) bool {
+    char := s.tally.corrections.chars[index]
+    return !char.Typed || char.FirstTry
+}
+
+
+// lessonTally counts the keystrokes of a lesson played without the TUI.
+type lessonTally struct {
+    correct     int
+    incorrect   int
+    duration    int64       // The time since the first keystroke in milliseconds
+    started     bool        // If a correct keystroke has been made
+    keystrokes  []keystrokeRecord
+    typed       []shared.KeystrokeRecord    // Every keystroke of the lesson in the order they were made
+    corrections correctionTracker
+}
+
+
+// newLessonTally returns a tally for a lesson of the given length using the
+// given error policy.
+func newLessonTally(policy string, length int) lessonTally {
+    return lessonTally{ corrections: newCorrectionTracker(policy, length) }
+}
+
+
+// record records a keystroke on the expected character at the index and
+// returns if it was correct and if the cursor should move on. The lesson
+// and its words are timed from the first keystroke of any kind, while the
+// attempts at characters are only timed after the first correct keystroke,
+// both matching the TUI.
+func (t *lessonTally) record(index int, expected, typed rune, elapsed int64) (bool, bool) {
+    if t.correct + t.incorrect > 0 {
+        t.duration += elapsed
+    }
+    if !t.started {
+        elapsed = -1
+    }
+
+    success := typed == expected
+    t.typed = append(t.typed, shared.KeystrokeRecord{
+        Expected: string(expected),
+        Typed: string(typed),
+        Retry: t.corrections.mistyped(index),
+    })
+    firstTry, advance := t.corrections.keystroke(index, success)
+    if !success {
+        recordConfusion(expected, typed)
+    }
+    if firstTry {
+        keystroke := recordKeystroke(expected, typed, elapsed)
+        t.keystrokes = append(t.keystrokes, keystroke)
+    }
+    if advance {
+        t.corrections.pass(index, t.duration)
+    }
+
+    if success {
+        t.started = true
+        t.correct++
+    } else {
+        t.incorrect++
+    }
+
+    return success, advance
+}
+
+
+// finish saves the accuracies, reviews the characters and bigrams of the
+// lesson and adds the lesson and the stats of its words to the history
+// together with the seed its words were generated from.
+func (t *lessonTally) finish(words string, seed int64) (shared.SessionRecord, error) {
+    if err := SaveCharacterAccuracies(); err != nil {
+        return shared.SessionRecord{}, err
+    }
+
+    if err := reviewLesson(t.keystrokes); err != nil {
+        return shared.SessionRecord{}, err
+    }
+
+    if err := saveConfusions(); err != nil {
+        return shared.SessionRecord{}, err
+    }
+
+    record := newSessionRecord(words, seed, t.correct, t.incorrect, t.duration)
+    record.Source = lessonSource(seed)
+    if record.Source == nil {
+        // The lesson was not generated last, so it can not be of the course
+        gameCtx.CourseStage = -1
+    }
+    record.FirstTryErrors, record.Corrected = t.corrections.stats()
+    record.Keystrokes = t.typed
+    recordWordStats(words, &t.corrections)
+    if err := recordSession(record); err != nil {
+        return shared.SessionRecord{}, err
+    }
+
+    if _, err := recordAchievements(record); err != nil {
+        return shared.SessionRecord{}, err
+    }
+
+    if _, err := recordCourseLesson(record.CPM / 5, record.Accuracy); err != nil {
+        return shared.SessionRecord{}, err
+    }
+
+    return record, nil
+}
+
+
+// CharacterStats returns a copy of the accuracy the player has with each
+// character.
+func CharacterStats() map[rune]shared.CharacterAccuracy {
+    stats := make(map[rune]shared.CharacterAccuracy, len(gameCtx.CharacterAccuracies))
+    for char, ca := range gameCtx.CharacterAccuracies {
+        stats[char] = ca
+    }
+
+    return stats
+}
+
+
+// KeyboardLayout returns the keyboard layout in the settings.
+func KeyboardLayout() layout.Layout {
+    return *gameCtx.Layout
+}
+
+
+// SessionHistory returns a copy of the finished lessons, oldest first.
+func SessionHistory() []shared.SessionRecord {
+    sessions := make([]shared.SessionRecord, len(history.Sessions))
+    copy(sessions, history.Sessions)
+
+    return sessions
+}
+
+
+// parseCharacter returns the single character in s.
+func parseCharacter(s string) (rune, error) {
+    if utf8.RuneCountInString(s) != 1 {
+        return 0, fmt.Errorf("expected a single character, got %q", s)
+    }
+
+    char, _ := utf8.DecodeRuneInString(s)
+    return char, nil
+}
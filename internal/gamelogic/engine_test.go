@@ -0,0 +1,164 @@
+package gamelogic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadTestGame loads a game which saves to a temporary directory and picks
+// its words from a small dictionary.
+func loadTestGame(t *testing.T) {
+    t.Helper()
+
+    dir := t.TempDir()
+    saveDir = dir
+    t.Cleanup(func() { saveDir = "." })
+
+    dictionaryPath := filepath.Join(dir, "words.txt")
+    if err := os.WriteFile(dictionaryPath, []byte("the\nsea\nare\nrise\nraise\nease\nseat\nrate\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    settings := fmt.Sprintf(`{ "dictionaryPath": %q, "numChars": 4, "wordCount": 3 }`, dictionaryPath)
+    if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte(settings), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    SetSeed(1)
+    if err := LoadGame(); err != nil {
+        t.Fatal(err)
+    }
+}
+
+
+// typedResults returns the results of typing every character of the words
+// correctly.
+func typedResults(words string, seed int64) LessonResults {
+    results := LessonResults{ Words: words, Seed: seed }
+    chars := []rune(words)
+    for _, char := range chars[:len(chars)-1] {
+        results.Keystrokes = append(results.Keystrokes, Keystroke{ Expected: string(char), Typed: string(char), Time: 100 })
+    }
+
+    return results
+}
+
+
+func TestSubmitResultsValidation(t *testing.T) {
+    loadTestGame(t)
+
+    tests := []struct {
+        name        string
+        results     LessonResults
+    }{
+        {
+            name: "no keystrokes",
+            results: LessonResults{ Words: "the sea " },
+        },
+        {
+            name: "fewer keystrokes than characters",
+            results: LessonResults{
+                Words: "the sea rise ",
+                Keystrokes: []Keystroke{ { Expected: "t", Typed: "t", Time: 100 } },
+            },
+        },
+        {
+            name: "more keystrokes than characters",
+            results: LessonResults{
+                Words: "a ",
+                Keystrokes: []Keystroke{
+                    { Expected: "a", Typed: "a", Time: 100 },
+                    { Expected: " ", Typed: " ", Time: 100 },
+                },
+            },
+        },
+        {
+            name: "keystroke expecting another character",
+            results: LessonResults{
+                Words: "at ",
+                Keystrokes: []Keystroke{
+                    { Expected: "a", Typed: "a", Time: 100 },
+                    { Expected: "s", Typed: "s", Time: 100 },
+                },
+            },
+        },
+        {
+            name: "keystroke expecting several characters",
+            results: LessonResults{
+                Words: "a ",
+                Keystrokes: []Keystroke{ { Expected: "ab", Typed: "a", Time: 100 } },
+            },
+        },
+        {
+            name: "characters outside of ascii counted as bytes",
+            results: LessonResults{
+                Words: "é ",
+                Keystrokes: []Keystroke{
+                    { Expected: "é", Typed: "é", Time: 100 },
+                    { Expected: " ", Typed: " ", Time: 100 },
+                },
+            },
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            sessions := len(history.Sessions)
+            if _, err := SubmitResults(test.results); err == nil {
+                t.Errorf("SubmitResults(%+v) succeeded, want an error", test.results)
+            }
+            if len(history.Sessions) != sessions {
+                t.Errorf("SubmitResults(%+v) added a lesson to the history", test.results)
+            }
+        })
+    }
+}
+
+
+func TestSubmitResultsSeed(t *testing.T) {
+    loadTestGame(t)
+
+    lesson, err := GenerateLesson(LessonParams{})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name        string
+        results     LessonResults
+        wantSeed    int64
+    }{
+        {
+            name: "generated words",
+            results: typedResults(lesson.Words, lesson.Seed),
+            wantSeed: lesson.Seed,
+        },
+        {
+            name: "other words with the seed",
+            results: typedResults("the sea ", lesson.Seed),
+            wantSeed: 0,
+        },
+        {
+            name: "generated words with another seed",
+            results: typedResults(lesson.Words, lesson.Seed + 1),
+            wantSeed: 0,
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            record, err := SubmitResults(test.results)
+            if err != nil {
+                t.Fatalf("SubmitResults(%+v) failed: %v", test.results, err)
+            }
+            if record.Seed != test.wantSeed {
+                t.Errorf("record.Seed = %d, want %d", record.Seed, test.wantSeed)
+            }
+            if record.Correct != len(test.results.Keystrokes) || record.Incorrect != 0 {
+                t.Errorf("record has %d correct and %d incorrect keystrokes, want %d and 0", record.Correct, record.Incorrect, len(test.results.Keystrokes))
+            }
+        })
+    }
+}
@@ -0,0 +1,71 @@
+package gamelogic
+
+import (
+	"strings"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+	"github.com/gdamore/tcell/v2"
+)
+
+// fingerStatsSessions is the amount of recent lessons the keystrokes per
+// finger and hand and the same finger and hand alternation rates are
+// computed from.
+const fingerStatsSessions = 20
+
+
+// showFingerStats shows the accuracy of each finger and hand of the layout in
+// the settings, and the errors, corrections and transitions between keys of
+// the keystrokes made in the recent lessons. Only pairs of correct keystrokes
+// which are not corrections count as transitions. Lessons recorded before
+// their keystrokes were kept are skipped.
+func showFingerStats() {
+    sessions := history.Sessions
+    if len(sessions) > fingerStatsSessions {
+        sessions = sessions[len(sessions)-fingerStatsSessions:]
+    }
+
+    var keystrokes []shared.KeystrokeRecord
+    var typed strings.Builder
+    for _, session := range sessions {
+        keystrokes = append(keystrokes, session.Keystrokes...)
+        for _, keystroke := range session.Keystrokes {
+            // Pairs with a wrong keystroke or a correction were not typed in
+            // a row, so they are split into separate words
+            correct := keystroke.Typed == keystroke.Expected
+            if !correct || keystroke.Retry {
+                typed.WriteRune(' ')
+            }
+            if correct {
+                typed.WriteString(keystroke.Typed)
+            }
+        }
+        typed.WriteRune(' ')
+    }
+
+    recentFingers, recentHands := gameCtx.Layout.CountKeystrokes(keystrokes)
+    graphicsCtx.ShowFingerStats(
+        gameCtx.Layout.Name,
+        gameCtx.Layout.FingerAccuracies(gameCtx.CharacterAccuracies),
+        gameCtx.Layout.HandAccuracies(gameCtx.CharacterAccuracies),
+        recentFingers,
+        recentHands,
+        gameCtx.Layout.CountTransitions(typed.String()),
+    )
+}
+
+
+// fingerStatsInputHandler handles the input on the finger stats screen.
+// <Enter> returns to the end screen.
+func fingerStatsInputHandler(event *tcell.EventKey) *tcell.EventKey {
+    if event.Key() == tcell.KeyEnter {
+        showEndScreen()
+        inputCaptureChangeChan <- endScreenInputHandler
+        return nil
+    } else if event.Key() == tcell.KeyEscape {
+        graphicsCtx.App.Stop()
+        return nil
+    }
+
+    return event
+}
+
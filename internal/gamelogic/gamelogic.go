@@ -5,44 +5,102 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
+	"time"
 
+	"github.com/Kaspetti/LayoutLearner/internal/course"
 	"github.com/Kaspetti/LayoutLearner/internal/dictionary"
 	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
+	"github.com/Kaspetti/LayoutLearner/internal/scoring"
 	"github.com/Kaspetti/LayoutLearner/internal/shared"
+	"github.com/Kaspetti/LayoutLearner/internal/srs"
 	"github.com/gdamore/tcell/v2"
 )
 
 // GameContext stores information of the game.
 type GameContext struct {
     Words               string                              // The words of the current game
+    Chars               []rune                              // The characters of the words of the current game, which the indices of the game refer to
+    Seed                int64                               // The seed the words of the current game were generated from
+    NextSeed            int64                               // The seed the words of the next game are generated from
+    Source              shared.LessonSource                 // What the words of the current game were generated from
     CurrentCharIndex    int                                 // The index of the character currently in play
+    Dictionary          *dictionary.Dictionary              // The dictionary the words of each lesson are picked from
     CharacterPriorities []rune                              // Slice of all characters in the dictionary sorted by priority
-    PriorityCharacter   rune                                // The priority character to include in each word
+    PriorityCharacters  []rune                              // The priority characters to include in the words, weakest first
     CurrentChars        []rune                              // Slice of the currently used characters in each lesson
     CharacterAccuracies map[rune]shared.CharacterAccuracy   // The accuracy the user has with each character
     Correct             int                                 // The amount of correctly written characters this round
     Incorrect           int                                 // The amount of incorrently written characters this round
+    Streak              int                                 // The amount of correct keystrokes in a row this round
+    BestStreak          int                                 // The longest streak of correct keystrokes this round
     Started             bool                                // Started becomes true the moment the player hits a button
     StartTimeCharacter  int64                               // The time when the current character went into play in milliseconds since unix
+    LessonStartTime     int64                               // The time of the first keystroke of the lesson in milliseconds since unix
+    KeyTimes            []int64                             // The time each character was passed in milliseconds since LessonStartTime
+    Ghost               []int64                             // The recorded run replayed by the ghost cursor, nil if there is none
+    Beat                int                                 // The last beat of the metronome in the current lesson, -1 before the first
+    Finished            bool                                // Finished becomes true when the player reaches the end of the words
+    Race                *RaceSession                        // The multiplayer race the player is part of, nil when playing alone
+    Scorer              scoring.Scorer                      // The strategy used to score each character
+    Layout              *layout.Layout                      // The keyboard layout used for the finger and hand stats
+    Theme               *graphics.Theme                     // The colors of the TUI
+    Keystrokes          []keystrokeRecord                   // The first keystroke on each character of the current lesson in the order they were made
+    Typed               []shared.KeystrokeRecord            // Every keystroke of the current lesson in the order they were made
+    Corrections         correctionTracker                   // Applies the error policy and tracks the corrections made in the current lesson
+    StartScores         map[rune]float64                    // The score of each current character when the current lesson started, -1 if never attempted
+    Report              graphics.LessonReport               // The report of the last finished lesson
+    Course              *course.Course                      // The course the lessons follow, nil for adaptive lessons
+    CourseStage         int                                 // The index of the course stage of the current lesson, -1 if it is not part of the course
     Settings            GameSettings                        // The settings for the game
 }
 
+// keystrokeRecord stores a single keystroke made in a lesson.
+type keystrokeRecord struct {
+    Expected            rune                                // The character the player should have typed
+    Typed               rune                                // The character the player typed
+    Correct             bool                                // If the typed character was the expected one
+    Time                int64                               // The time since the previous keystroke in milliseconds, -1 if it was not timed
+}
+
 // GameSettings stores the settings for the game. AccuracyWeight and TimeWeight should add up to 1.0
 type GameSettings struct {
-    NumChars            int                                 // The number of characters to use from CharacterPriorities
-    MaxWordLength       int                                 // The max word length (inclusive)
-    MinWordLength       int                                 // The min word length (inclusive)
-    WordCount           int                                 // The amount of words to include in the lesson
-    TargetCPM           int                                 // The target "characters per minute" used for scoring
-    AccuracyWeight      float64                             // The weight at which accuracy affects the final score
-    TimeWeight          float64                             // The weight at which speed affects the final score
+    NumChars            int         `json:"numChars"`           // The number of characters to use from CharacterPriorities
+    MaxWordLength       int         `json:"maxWordLength"`      // The max word length (inclusive)
+    MinWordLength       int         `json:"minWordLength"`      // The min word length (inclusive)
+    WordCount           int         `json:"wordCount"`          // The amount of words to include in the lesson
+    TargetCPM           int         `json:"targetCPM"`          // The target "characters per minute" used for scoring
+    AccuracyWeight      float64     `json:"accuracyWeight"`     // The weight at which accuracy affects the final score
+    TimeWeight          float64     `json:"timeWeight"`         // The weight at which speed affects the final score
+    GhostMode           string      `json:"ghostMode"`          // Which recorded run the ghost cursor replays (best, previous or off)
+    Scorer              string      `json:"scorer"`             // The scoring strategy (linear, ema, windowed or bayesian)
+    Scheduler           string      `json:"scheduler"`          // How the priority characters are chosen (score or srs)
+    PriorityCount       int         `json:"priorityCount"`      // The amount of priority characters targeted in each lesson
+    EMAAlpha            float64     `json:"emaAlpha"`           // The smoothing factor of the ema scoring strategy
+    ScoreWindow         int         `json:"scoreWindow"`        // The amount of recent attempts used by the windowed scoring strategy
+    ErrorPolicy         string      `json:"errorPolicy"`        // How errors are corrected (free, stop or strict)
+    Layout              string      `json:"layout"`             // The name of a built-in keyboard layout or the path to a layout file
+    DictionaryPath      string      `json:"dictionaryPath"`     // The path of the dictionary the characters and words of the lessons come from
+    DictionaryFilter    dictionary.Filter `json:"dictionaryFilter"` // Which words of the dictionary are used and how they are normalised
+    UnlockOrder         string      `json:"unlockOrder"`        // The order characters are unlocked in (frequency, layout or custom)
+    UnlockSequence      string      `json:"unlockSequence"`     // The characters to unlock first when UnlockOrder is custom
+    Theme               string      `json:"theme"`              // The name of a built-in theme or the path to a theme file
+    PaceCaret           bool        `json:"paceCaret"`          // If a caret moving at the target CPM is shown in the words
+    Metronome           bool        `json:"metronome"`          // If the terminal bell rings for every character at the target CPM
+    Goals               DailyGoals  `json:"goals"`              // The minutes, lessons and words per minute to reach every day
+    Course              string      `json:"course"`             // The path of a course file to follow, empty for adaptive lessons
 }
 
 
 var gameCtx     GameContext
 var graphicsCtx graphics.GraphicsContext
 
+// firstSeed is the seed of the first lesson. Each following lesson uses the
+// next seed.
+var firstSeed = time.Now().UnixNano()
+
 
 // Channel for handling changes in the input capture. This is handled by a channel 
 // and a goroutine as changing the input capture function does not work as 
@@ -53,44 +111,126 @@ var inputCaptureChangeChan = make(chan func(*tcell.EventKey) *tcell.EventKey)
 // StartGame starts the game. It gets the character priorities of the 
 // dictionary in use and creates the tview application and textview.
 // It then creates a fresh game context and starts the goroutine for
-// handling input capture function changes.
+// handling input capture function changes. The progress towards the
+// daily goals is shown before the first lesson.
 func StartGame() error {
-    characterPriority, err := dictionary.GetCharacterPriority("resources/words.txt")
+    if err := initGame(); err != nil {
+        return err
+    }
+
+    initGraphics()
+    showStartScreen()
+    graphicsCtx.App.SetInputCapture(startScreenInputHandler)
+
+    return runApp()
+}
+
+
+// SetSeed sets the seed the words of the first lesson are generated from.
+// Each following lesson uses the next seed, so given the same dictionary,
+// settings and saved progress the same lessons are generated. Must be called
+// before the game is started.
+func SetSeed(seed int64) {
+    firstSeed = seed
+}
+
+
+// initGame creates a fresh game context using the character priorities of
+// the dictionary in use and the saved progress of the player.
+func initGame() error {
+    settings, err := loadSettings()
     if err != nil {
         return err
     }
 
-    var charAccuracies map[rune]shared.CharacterAccuracy
-    if _, err := os.Stat("accuracies"); errors.Is(err, os.ErrNotExist) {
-        charAccuracies = make(map[rune]shared.CharacterAccuracy)
-    } else {
-        saveData, err := os.ReadFile("accuracies")
-        if err != nil {
-            return err
-        }
+    dict, err := dictionary.Load(settings.DictionaryPath, settings.DictionaryFilter)
+    if err != nil {
+        return err
+    }
+    characterPriority := dict.CharacterPriority()
 
-        if err := json.Unmarshal(saveData, &charAccuracies); err != nil {
+    charAccuracies, err := loadCharacterAccuracies()
+    if err != nil {
+        return err
+    }
+
+    scorer, err := newScorer(settings)
+    if err != nil {
+        return err
+    }
+
+    keyboardLayout, err := layout.Load(settings.Layout)
+    if err != nil {
+        return err
+    }
+
+    characterPriority = unlockOrder(characterPriority, settings, keyboardLayout)
+
+    theme, err := graphics.LoadTheme(settings.Theme)
+    if err != nil {
+        return err
+    }
+
+    var lessonCourse *course.Course
+    if settings.Course != "" {
+        lessonCourse, err = course.Load(settings.Course)
+        if err != nil {
             return err
         }
     }
 
     gameCtx = GameContext{
+        Dictionary: dict,
         CharacterPriorities: characterPriority,
         CharacterAccuracies: charAccuracies,
-        
-        Settings: GameSettings{
-            NumChars: 5,
-            MinWordLength: 3,
-            MaxWordLength: 5,
-            WordCount: 10,
-            TargetCPM: 250,
-            TimeWeight: 0.5,
-            AccuracyWeight: 0.5,
-        },
-    }
-
-    graphicsCtx = graphics.InitializeGraphics()
+        Settings: settings,
+        Scorer: scorer,
+        Layout: keyboardLayout,
+        Theme: theme,
+        Course: lessonCourse,
+        CourseStage: -1,
+        NextSeed: firstSeed,
+    }
+
+    // The scores are recomputed in case the scoring strategy has changed
+    // since they were saved
+    recomputeScores()
+
+    if err := loadGhostRecords(); err != nil {
+        return err
+    }
+
+    if err := loadConfusions(); err != nil {
+        return err
+    }
+
+    if err := loadAchievements(); err != nil {
+        return err
+    }
+
+    if err := loadCourseProgress(); err != nil {
+        return err
+    }
+
+    if err := loadHistory(); err != nil {
+        return err
+    }
+
+    // The goals may have been changed to ones already met today
+    updateStreak(time.Now())
+
+    return nil
+}
+
+
+// initGraphics creates the tview application and starts the goroutines for
+// handling input capture function changes, moving the ghost cursor and, if
+// enabled, ringing the metronome. The layout adapts to the size of the
+// terminal before every frame.
+func initGraphics() {
+    graphicsCtx = graphics.InitializeGraphics(gameCtx.Theme)
     graphicsCtx.App.SetInputCapture(gameInputHandler)
+    graphicsCtx.App.SetBeforeDrawFunc(graphicsCtx.HandleResize)
 
     // Sets up the goroutine for handling switching of input capture functions
     go func() {
@@ -102,64 +242,141 @@ func StartGame() error {
         }
     }()
 
-    newGame()
-    if err := graphicsCtx.App.SetRoot(graphicsCtx.MainFlex, true).Run(); err != nil {
-        return err
+    go runGhostTicker()
+    if gameCtx.Settings.Metronome {
+        go runMetronome()
     }
+}
 
-    return nil
+
+// runApp runs the tview application until the player exits the game.
+func runApp() error {
+    return graphicsCtx.App.SetRoot(graphicsCtx.MainFlex, true).Run()
 }
 
 
 // newGame resets the game gontext by generating new words from the 
 // character priority and resetting the other fields to their original value.
 func newGame() {
+    if replaySeed != 0 {
+        words, err := replayLesson(replaySeed)
+        replaySeed = 0
+        if err != nil {
+            graphicsCtx.ShowErrorScreen("replaying lesson", err)
+            inputCaptureChangeChan <- endScreenInputHandler
+            return
+        }
+
+        startLesson(words)
+        return
+    }
+
+    words, err := generateWords()
+    if err != nil {
+        graphicsCtx.ShowErrorScreen("generating new words", err)
+        inputCaptureChangeChan <- endScreenInputHandler 
+        return
+    }
+
+    startLesson(words)
+}
+
+
+// generateWords generates the words of a new lesson from the current
+// characters, including at least one priority character in every word.
+// While a course is followed the words come from its current stage instead.
+func generateWords() (string, error) {
+    if stage, ok := courseStage(); ok {
+        s := gameCtx.Settings
+        return generateStageWords(stage, nextSeed(), s.MinWordLength, s.MaxWordLength, s.WordCount), nil
+    }
+    gameCtx.CourseStage = -1
+
+    if gameCtx.Settings.NumChars > len(gameCtx.CharacterPriorities) {
+        return "", fmt.Errorf("numChars is %d but the dictionary only has %d characters", gameCtx.Settings.NumChars, len(gameCtx.CharacterPriorities))
+    }
+
     gameCtx.CurrentChars = gameCtx.CharacterPriorities[:gameCtx.Settings.NumChars]
-    gameCtx.PriorityCharacter = getPriorityCharacter()
+    gameCtx.PriorityCharacters = getPriorityCharacters(gameCtx.Settings.PriorityCount)
 
-    wordsList, err := dictionary.GetWordsFromChars(
-        "resources/words.txt", 
+    gameCtx.Seed = nextSeed()
+    setSource("", gameCtx.Settings.MinWordLength, gameCtx.Settings.MaxWordLength)
+    wordsList := gameCtx.Dictionary.WordsFromTargets(
+        rand.New(rand.NewSource(gameCtx.Seed)),
         gameCtx.CurrentChars, 
-        gameCtx.PriorityCharacter, 
+        priorityWeights(gameCtx.PriorityCharacters), 
         gameCtx.Settings.MinWordLength, 
         gameCtx.Settings.MaxWordLength, 
         gameCtx.Settings.WordCount,
     )
-    if err != nil {
-        graphicsCtx.ShowErrorScreen("generating new words", err)
-        inputCaptureChangeChan <- endScreenInputHandler 
-        return
-    }
 
+    return joinWords(wordsList), nil
+}
+
+
+// nextSeed returns the seed for generating the next lesson.
+func nextSeed() int64 {
+    seed := gameCtx.NextSeed
+    gameCtx.NextSeed++
+
+    return seed
+}
+
+
+// joinWords joins the words of a lesson, ending each word with a space.
+func joinWords(wordsList []string) string {
     words := ""
     for _, word := range wordsList {
         words += fmt.Sprintf("%s ", word)
     }
 
-    colorMap := make([]string, len(words))
-    for i := 0; i < len(words); i++ {
-        colorMap[i] = "white"
+    return words
+}
+
+
+// retryLesson starts the lesson which was just played again, letting the
+// player race against the ghost of their earlier runs.
+func retryLesson() {
+    startLesson(gameCtx.Words)
+}
+
+
+// startLesson resets the game context and starts a lesson with the given words.
+func startLesson(words string) {
+    chars := []rune(words)
+    colorMap := make([]string, len(chars))
+    for i := 0; i < len(chars); i++ {
+        colorMap[i] = graphics.CharUntyped
     }
 
+    initCharacterAccuracies()
+
+    gameCtx.StartScores = make(map[rune]float64, len(gameCtx.CurrentChars))
     for _, char := range gameCtx.CurrentChars {
-        if _, ok := gameCtx.CharacterAccuracies[char]; !ok {
-            gameCtx.CharacterAccuracies[char] = shared.CharacterAccuracy {
-                Attempts: 0,
-                Correct: 0,
-                Score: -1,
-            }
-        }
+        gameCtx.StartScores[char] = gameCtx.CharacterAccuracies[char].Score
     }
 
     gameCtx.Words = words
+    gameCtx.Chars = chars
     graphicsCtx.MainColorMap = colorMap
     gameCtx.CurrentCharIndex = 0
     gameCtx.Correct = 0
     gameCtx.Incorrect = 0
+    gameCtx.Streak = 0
+    gameCtx.BestStreak = 0
     gameCtx.Started = false
-
+    gameCtx.Finished = false
+    gameCtx.LessonStartTime = 0
+    gameCtx.Beat = -1
+    gameCtx.KeyTimes = make([]int64, len(chars))
+    gameCtx.Keystrokes = nil
+    gameCtx.Typed = nil
+    gameCtx.Corrections = newCorrectionTracker(gameCtx.Settings.ErrorPolicy, len(chars))
+    gameCtx.Ghost = selectGhost(words)
+
+    updateGhost()
     graphicsCtx.MainTextView.Highlight("0")
-    graphicsCtx.DrawText(gameCtx.Words, gameCtx.PriorityCharacter, gameCtx.CurrentChars, gameCtx.CharacterAccuracies)
+    drawGame()
 
     if err := SaveCharacterAccuracies(); err != nil {
         log.Fatalln(err)
@@ -169,65 +386,175 @@ func newGame() {
 }
 
 
-// updateAccuracy updates the accuracy of a rune given if the attempt
-// was a success or not
-func updateAccuracy(char rune, success bool) {
-    ca := gameCtx.CharacterAccuracies[char]
-    ca.Attempts++
-    
-    if success {
-        ca.Correct++
+// initCharacterAccuracies adds an empty accuracy for each current character
+// which has not been attempted before.
+func initCharacterAccuracies() {
+    for _, char := range gameCtx.CurrentChars {
+        initCharacterAccuracy(char)
     }
+}
 
-    // Make sure to handle the case where Attempts is zero to avoid division by zero
-    if ca.Attempts > 0 {
-        ca.Accuracy = float64(ca.Correct) / float64(ca.Attempts)
-    } else {
-        ca.Accuracy = 0.0
+
+// initCharacterAccuracy adds an empty accuracy for the character if it has
+// not been attempted before.
+func initCharacterAccuracy(char rune) {
+    if _, ok := gameCtx.CharacterAccuracies[char]; !ok {
+        gameCtx.CharacterAccuracies[char] = shared.CharacterAccuracy {
+            Attempts: 0,
+            Correct: 0,
+            Score: -1,
+        }
     }
+}
 
-    // Get the target speed per character in ms depending on the TargetCPM
-    targetSpeedMs := 60000 / gameCtx.Settings.TargetCPM
-    lowerBound := targetSpeedMs / 2
-    speed := ca.AverageTime
-    if speed < int64(lowerBound) {
-        speed = int64(lowerBound)
+
+// drawGame draws the words and the information panel of the current lesson.
+func drawGame() {
+    updatePace()
+    graphicsCtx.DrawText(gameCtx.Words, gameCtx.PriorityCharacters, sessionStats())
+}
+
+
+// showEndScreen shows the end screen with the report of the finished lesson.
+func showEndScreen() {
+    graphicsCtx.ShowEndScreen(gameCtx.Report, gameCtx.Settings.GhostMode)
+}
+
+
+// switchTheme changes the colors of the TUI to the theme with the given
+// name and redraws the info panel and the end screen in them.
+func switchTheme(name string) {
+    theme, err := graphics.LoadTheme(name)
+    if err != nil {
+        graphicsCtx.ShowErrorScreen("loading theme", err)
+        return
     }
-    speedScore := 1 - (float64(speed - int64(lowerBound)) / float64(targetSpeedMs - lowerBound))
 
-    ca.Score = (ca.Accuracy * gameCtx.Settings.AccuracyWeight) + (speedScore * gameCtx.Settings.TimeWeight)
+    gameCtx.Theme = theme
+    gameCtx.Settings.Theme = name
+    graphicsCtx.SetTheme(theme)
+    drawGame()
+    showEndScreen()
+}
+
+
+// recordKeystroke records an attempt at typing the expected character. The
+// time spent on the attempt in milliseconds is added to the character's
+// average if the attempt was a success. A negative elapsed time means the
+// attempt is not timed, as is the case before the first correct keystroke
+// of a lesson.
+func recordKeystroke(expected, typed rune, elapsed int64) keystrokeRecord {
+    success := typed == expected
+    ca := gameCtx.CharacterAccuracies[expected]
+    attempt := addAttempt(&ca, success, elapsed)
 
     // Update or add the character accuracy in the map
-    gameCtx.CharacterAccuracies[char] = ca
+    gameCtx.CharacterAccuracies[expected] = ca
+
+    return keystrokeRecord{
+        Expected: expected,
+        Typed: typed,
+        Correct: success,
+        Time: attempt.Time,
+    }
 }
 
 
-func getPriorityCharacter() rune {
-    least := 1.0
-    priorityChar := gameCtx.CurrentChars[0]
+// addAttempt adds an attempt to the accuracy of a character and scores it
+// again. A negative elapsed time means the attempt is not timed.
+func addAttempt(ca *shared.CharacterAccuracy, success bool, elapsed int64) shared.Attempt {
+    ca.Attempts++
+
+    attempt := shared.Attempt{ Correct: success, Time: -1 }
+    if success {
+        ca.Correct++
 
+        if elapsed >= 0 {
+            ca.TotalTime += elapsed
+            ca.AverageTime = ca.TotalTime / ca.Attempts
+            attempt.Time = elapsed
+        }
+    }
+
+    ca.Accuracy = float64(ca.Correct) / float64(ca.Attempts)
+    ca.History = scoring.AddAttempt(ca.History, attempt)
+    ca.Score = gameCtx.Scorer.Score(*ca)
+
+    return attempt
+}
+
+
+// recomputeScores scores every attempted character again with the current
+// scoring strategy.
+func recomputeScores() {
     for char, ca := range gameCtx.CharacterAccuracies {
-        if char == ' ' {
+        if ca.Attempts == 0 {
             continue
         }
 
-        if ca.Score < least {
-            least = ca.Score
-            priorityChar = char 
-        }
+        ca.Score = gameCtx.Scorer.Score(ca)
+        gameCtx.CharacterAccuracies[char] = ca
+    }
+}
+
+
+// accuraciesSave is the content of the accuracies file, the accuracy of each
+// character stored alongside the review schedule of the characters and
+// bigrams. Files saved before the schedule was added only hold the map of
+// accuracies.
+type accuraciesSave struct {
+    Accuracies  map[rune]shared.CharacterAccuracy   `json:"accuracies"`     // The accuracy of each character
+    Schedule    srs.Schedule                        `json:"schedule"`       // The review schedule of the characters and bigrams
+}
+
+
+// loadCharacterAccuracies loads the accuracies from the accuracies file and
+// the review schedule stored with them. If no file exists no character has
+// been attempted and the schedule is empty.
+func loadCharacterAccuracies() (map[rune]shared.CharacterAccuracy, error) {
+    schedule = make(srs.Schedule)
+    if _, err := os.Stat(savePath("accuracies")); errors.Is(err, os.ErrNotExist) {
+        return make(map[rune]shared.CharacterAccuracy), nil
     }
 
-    return priorityChar
+    saveData, err := os.ReadFile(savePath("accuracies"))
+    if err != nil {
+        return nil, err
+    }
+
+    var save accuraciesSave
+    if err := json.Unmarshal(saveData, &save); err != nil {
+        return nil, err
+    }
+    if save.Schedule != nil {
+        schedule = save.Schedule
+    }
+    if save.Accuracies != nil {
+        return save.Accuracies, nil
+    }
+
+    // The file holds only the accuracies
+    var charAccuracies map[rune]shared.CharacterAccuracy
+    if err := json.Unmarshal(saveData, &charAccuracies); err != nil {
+        return nil, err
+    }
+    if charAccuracies == nil {
+        charAccuracies = make(map[rune]shared.CharacterAccuracy)
+    }
+
+    return charAccuracies, nil
 }
 
 
+// SaveCharacterAccuracies writes the accuracies and the review schedule to
+// the accuracies file.
 func SaveCharacterAccuracies() error {
-    b, err := json.Marshal(gameCtx.CharacterAccuracies)
+    b, err := json.Marshal(accuraciesSave{ Accuracies: gameCtx.CharacterAccuracies, Schedule: schedule })
     if err != nil {
         return err
     }   
 
-    file, errs := os.Create("accuracies")
+    file, errs := os.Create(savePath("accuracies"))
     if errs != nil {
         return err
     }
@@ -242,12 +569,28 @@ func SaveCharacterAccuracies() error {
 }
 
 
+// deleteSave clears the progress of the player: the character accuracies,
+// the mistyped keys, the review schedule, the word stats and the course
+// progress. The finished lessons, the streak and the achievements are kept.
 func deleteSave() error {
-    if err := os.Remove("accuracies"); err != nil {
+    if err := os.Remove(savePath("accuracies")); err != nil {
         return err
     }
 
     gameCtx.CharacterAccuracies = make(map[rune]shared.CharacterAccuracy)
 
+    if err := deleteConfusions(); err != nil {
+        return err
+    }
+
+    if err := deleteWordStats(); err != nil {
+        return err
+    }
+
+    if err := deleteCourseProgress(); err != nil {
+        return err
+    }
+    deleteSchedule()
+
     return nil
 }
@@ -0,0 +1,176 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// Ghost modes selectable in GameSettings.GhostMode.
+const (
+    GhostOff        = "off"         // No ghost is shown
+    GhostBest       = "best"        // Replays the fastest completed run of the lesson
+    GhostPrevious   = "previous"    // Replays the latest completed run of the lesson
+)
+
+// maxGhostRecords is the max amount of lessons to keep ghost runs for. The least
+// recently played lessons are dropped when the limit is reached.
+const maxGhostRecords = 50
+
+// ghostTickInterval is how often the ghost cursor is moved forward.
+const ghostTickInterval = 50 * time.Millisecond
+
+
+// GhostRecord stores the recorded runs of a single lesson text. A run is a slice
+// of times in milliseconds since the first keystroke at which each character was
+// passed.
+type GhostRecord struct {
+    Best        []int64     `json:"best"`           // The fastest completed run
+    Previous    []int64     `json:"previous"`       // The latest completed run
+    LastPlayed  int64       `json:"lastPlayed"`     // The time the lesson was last completed in milliseconds since unix
+}
+
+
+var ghostRecords map[string]GhostRecord
+
+
+// loadGhostRecords loads the ghost runs from the ghosts file. If no file
+// exists an empty set of records is used.
+func loadGhostRecords() error {
+    ghostRecords = make(map[string]GhostRecord)
+    if _, err := os.Stat(savePath("ghosts")); errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+
+    saveData, err := os.ReadFile(savePath("ghosts"))
+    if err != nil {
+        return err
+    }
+
+    return json.Unmarshal(saveData, &ghostRecords)
+}
+
+
+// saveGhostRecords writes the ghost runs to the ghosts file.
+func saveGhostRecords() error {
+    b, err := json.Marshal(ghostRecords)
+    if err != nil {
+        return err
+    }
+
+    return os.WriteFile(savePath("ghosts"), b, 0644)
+}
+
+
+// nextGhostMode returns the ghost mode following mode in the cycle
+// best -> previous -> off.
+func nextGhostMode(mode string) string {
+    switch mode {
+    case GhostBest:
+        return GhostPrevious
+    case GhostPrevious:
+        return GhostOff
+    default:
+        return GhostBest
+    }
+}
+
+
+// selectGhost returns the run to replay for the given lesson text according
+// to the ghost mode in the settings. Returns nil if there is no run to replay.
+func selectGhost(words string) []int64 {
+    record, ok := ghostRecords[words]
+    if !ok {
+        return nil
+    }
+
+    switch gameCtx.Settings.GhostMode {
+    case GhostBest:
+        return record.Best
+    case GhostPrevious:
+        return record.Previous
+    default:
+        return nil
+    }
+}
+
+
+// recordGhostRun stores the keystroke timings of the finished lesson as the
+// previous run of the lesson text, and as the best run if it was faster than
+// the current best.
+func recordGhostRun() error {
+    run := gameCtx.KeyTimes[:len(gameCtx.Chars)-1]
+    record := ghostRecords[gameCtx.Words]
+
+    record.Previous = run
+    if record.Best == nil || run[len(run)-1] < record.Best[len(record.Best)-1] {
+        record.Best = run
+    }
+    record.LastPlayed = time.Now().UnixMilli()
+    ghostRecords[gameCtx.Words] = record
+
+    if len(ghostRecords) > maxGhostRecords {
+        lessons := make([]string, 0, len(ghostRecords))
+        for lesson := range ghostRecords {
+            lessons = append(lessons, lesson)
+        }
+        sort.Slice(lessons, func(i, j int) bool {
+            return ghostRecords[lessons[i]].LastPlayed < ghostRecords[lessons[j]].LastPlayed
+        })
+
+        for _, lesson := range lessons[:len(lessons)-maxGhostRecords] {
+            delete(ghostRecords, lesson)
+        }
+    }
+
+    return saveGhostRecords()
+}
+
+
+// updateGhost moves the ghost cursor to the position the ghost run was at
+// given the time since the first keystroke of the lesson. It also updates
+// how far ahead of the ghost the player is in milliseconds.
+func updateGhost() {
+    if gameCtx.Ghost == nil {
+        graphicsCtx.GhostIndex = -1
+        return
+    }
+
+    if gameCtx.LessonStartTime == 0 {
+        graphicsCtx.GhostIndex = 0
+        graphicsCtx.GhostLead = 0
+        return
+    }
+
+    elapsed := time.Now().UnixMilli() - gameCtx.LessonStartTime
+    ghostIndex := sort.Search(len(gameCtx.Ghost), func(i int) bool {
+        return gameCtx.Ghost[i] > elapsed
+    })
+    graphicsCtx.GhostIndex = ghostIndex
+
+    // The lead is the time left until the ghost passes the player's current
+    // character. It is negative when the ghost has already passed it.
+    if gameCtx.CurrentCharIndex < len(gameCtx.Ghost) {
+        graphicsCtx.GhostLead = gameCtx.Ghost[gameCtx.CurrentCharIndex] - elapsed
+    }
+}
+
+
+// runGhostTicker periodically moves the ghost cursor and redraws the game
+// while a lesson is in progress, which also moves the pace caret and keeps
+// the elapsed time of the session stats current.
+func runGhostTicker() {
+    ticker := time.NewTicker(ghostTickInterval)
+    for range ticker.C {
+        graphicsCtx.App.QueueUpdateDraw(func() {
+            if gameCtx.LessonStartTime == 0 || gameCtx.Finished {
+                return
+            }
+
+            updateGhost()
+            drawGame()
+        })
+    }
+}
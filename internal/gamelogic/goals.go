@@ -0,0 +1,140 @@
+package gamelogic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/gdamore/tcell/v2"
+)
+
+// dayFormat is the layout of the days stored in the streak.
+const dayFormat = "2006-01-02"
+
+
+// DailyGoals stores the goals the player sets for every day. A goal of 0 is
+// not tracked.
+type DailyGoals struct {
+    Minutes     float64     `json:"minutes"`        // The minutes to spend typing each day
+    Lessons     int         `json:"lessons"`        // The amount of lessons to finish each day
+    WPM         float64     `json:"wpm"`            // The words per minute to reach in at least one lesson each day
+}
+
+
+// Streak stores the amount of days in a row the player has met their daily
+// goals.
+type Streak struct {
+    Current     int         `json:"current"`        // The length of the streak ending on LastDay
+    Best        int         `json:"best"`           // The longest streak so far
+    LastDay     string      `json:"lastDay"`        // The last day the goals were met, empty if they never were
+}
+
+
+// validate checks that none of the goals are negative.
+func (g DailyGoals) validate() error {
+    if g.Minutes < 0 || g.Lessons < 0 || g.WPM < 0 {
+        return fmt.Errorf("goals must not be negative, got %v minutes, %d lessons and %v wpm", g.Minutes, g.Lessons, g.WPM)
+    }
+
+    return nil
+}
+
+
+// dailyProgress returns the progress towards the daily goals on the day of
+// the given time, counting every lesson of the history finished that day.
+func dailyProgress(now time.Time) graphics.DailyProgress {
+    goals := gameCtx.Settings.Goals
+    progress := graphics.DailyProgress{
+        MinutesGoal: goals.Minutes,
+        LessonsGoal: goals.Lessons,
+        WPMGoal: goals.WPM,
+    }
+
+    today := now.Format(dayFormat)
+    for _, session := range history.Sessions {
+        if time.UnixMilli(session.Time).Format(dayFormat) != today {
+            continue
+        }
+
+        progress.Lessons++
+        progress.Minutes += float64(session.Duration) / 60000
+        if wpm := session.CPM / 5; wpm > progress.BestWPM {
+            progress.BestWPM = wpm
+        }
+    }
+
+    progress.Streak = currentStreak(now)
+    progress.BestStreak = history.Streak.Best
+    progress.GoalsMet = goalsMet(progress)
+
+    return progress
+}
+
+
+// goalsMet returns if the progress meets every daily goal. Without any goals
+// a single lesson is enough.
+func goalsMet(progress graphics.DailyProgress) bool {
+    if progress.MinutesGoal == 0 && progress.LessonsGoal == 0 && progress.WPMGoal == 0 {
+        return progress.Lessons > 0
+    }
+
+    return progress.Minutes >= progress.MinutesGoal &&
+        progress.Lessons >= progress.LessonsGoal &&
+        progress.BestWPM >= progress.WPMGoal
+}
+
+
+// updateStreak extends the streak the first time the daily goals are met on
+// the day of the given time. The streak starts over if the goals were not met
+// the day before.
+func updateStreak(now time.Time) {
+    today := now.Format(dayFormat)
+    if history.Streak.LastDay == today || !goalsMet(dailyProgress(now)) {
+        return
+    }
+
+    if history.Streak.LastDay == now.AddDate(0, 0, -1).Format(dayFormat) {
+        history.Streak.Current++
+    } else {
+        history.Streak.Current = 1
+    }
+    history.Streak.LastDay = today
+
+    if history.Streak.Current > history.Streak.Best {
+        history.Streak.Best = history.Streak.Current
+    }
+}
+
+
+// currentStreak returns the length of the streak on the day of the given
+// time. A streak is kept until the end of the day after it was last
+// extended.
+func currentStreak(now time.Time) int {
+    lastDay := history.Streak.LastDay
+    if lastDay == now.Format(dayFormat) || lastDay == now.AddDate(0, 0, -1).Format(dayFormat) {
+        return history.Streak.Current
+    }
+
+    return 0
+}
+
+
+// showStartScreen shows the progress towards the daily goals of today.
+func showStartScreen() {
+    graphicsCtx.ShowStartScreen(dailyProgress(time.Now()))
+}
+
+
+// startScreenInputHandler handles the input on the start screen. <Enter>
+// starts the first lesson and <Escape> stops the game.
+func startScreenInputHandler(event *tcell.EventKey) *tcell.EventKey {
+    if event.Key() == tcell.KeyEnter {
+        newGame()
+        return nil
+    } else if event.Key() == tcell.KeyEscape {
+        graphicsCtx.App.Stop()
+        return nil
+    }
+
+    return event
+}
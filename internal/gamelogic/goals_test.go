@@ -0,0 +1,135 @@
+package gamelogic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+func TestGoalsMet(t *testing.T) {
+    tests := []struct {
+        name        string
+        progress    graphics.DailyProgress
+        want        bool
+    }{
+        { name: "no goals and no lessons", progress: graphics.DailyProgress{}, want: false },
+        { name: "no goals and a lesson", progress: graphics.DailyProgress{ Lessons: 1 }, want: true },
+        {
+            name: "every goal met",
+            progress: graphics.DailyProgress{ Minutes: 10, Lessons: 3, BestWPM: 40, MinutesGoal: 10, LessonsGoal: 3, WPMGoal: 40 },
+            want: true,
+        },
+        {
+            name: "minutes goal not met",
+            progress: graphics.DailyProgress{ Minutes: 9, Lessons: 3, BestWPM: 40, MinutesGoal: 10, LessonsGoal: 3, WPMGoal: 40 },
+            want: false,
+        },
+        {
+            name: "lessons goal not met",
+            progress: graphics.DailyProgress{ Minutes: 10, Lessons: 2, BestWPM: 40, MinutesGoal: 10, LessonsGoal: 3, WPMGoal: 40 },
+            want: false,
+        },
+        {
+            name: "wpm goal not met",
+            progress: graphics.DailyProgress{ Minutes: 10, Lessons: 3, BestWPM: 39, MinutesGoal: 10, LessonsGoal: 3, WPMGoal: 40 },
+            want: false,
+        },
+        {
+            name: "untracked goals",
+            progress: graphics.DailyProgress{ Minutes: 1, Lessons: 1, WPMGoal: 0, MinutesGoal: 1 },
+            want: true,
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            if got := goalsMet(test.progress); got != test.want {
+                t.Errorf("goalsMet(%+v) = %t, want %t", test.progress, got, test.want)
+            }
+        })
+    }
+}
+
+
+func TestUpdateStreak(t *testing.T) {
+    now := time.Date(2024, 3, 10, 18, 0, 0, 0, time.Local)
+    today := now.Format(dayFormat)
+    yesterday := now.AddDate(0, 0, -1).Format(dayFormat)
+    lastWeek := now.AddDate(0, 0, -7).Format(dayFormat)
+
+    // A lesson of five minutes at 40 words per minute
+    lesson := shared.SessionRecord{ Time: now.Add(-time.Hour).UnixMilli(), Duration: 5 * 60000, CPM: 200 }
+    oldLesson := shared.SessionRecord{ Time: now.AddDate(0, 0, -1).UnixMilli(), Duration: 5 * 60000, CPM: 200 }
+
+    tests := []struct {
+        name        string
+        goals       DailyGoals
+        sessions    []shared.SessionRecord
+        streak      Streak
+        want        Streak
+    }{
+        {
+            name: "first day",
+            sessions: []shared.SessionRecord{ lesson },
+            want: Streak{ Current: 1, Best: 1, LastDay: today },
+        },
+        {
+            name: "no lessons today",
+            sessions: []shared.SessionRecord{ oldLesson },
+            streak: Streak{ Current: 2, Best: 2, LastDay: yesterday },
+            want: Streak{ Current: 2, Best: 2, LastDay: yesterday },
+        },
+        {
+            name: "goals not met",
+            goals: DailyGoals{ Lessons: 2 },
+            sessions: []shared.SessionRecord{ lesson },
+            streak: Streak{ Current: 2, Best: 2, LastDay: yesterday },
+            want: Streak{ Current: 2, Best: 2, LastDay: yesterday },
+        },
+        {
+            name: "streak extended",
+            goals: DailyGoals{ Minutes: 5, WPM: 40 },
+            sessions: []shared.SessionRecord{ oldLesson, lesson },
+            streak: Streak{ Current: 2, Best: 2, LastDay: yesterday },
+            want: Streak{ Current: 3, Best: 3, LastDay: today },
+        },
+        {
+            name: "streak extended below the best",
+            sessions: []shared.SessionRecord{ lesson },
+            streak: Streak{ Current: 2, Best: 5, LastDay: yesterday },
+            want: Streak{ Current: 3, Best: 5, LastDay: today },
+        },
+        {
+            name: "streak started over",
+            sessions: []shared.SessionRecord{ lesson },
+            streak: Streak{ Current: 4, Best: 4, LastDay: lastWeek },
+            want: Streak{ Current: 1, Best: 4, LastDay: today },
+        },
+        {
+            name: "already extended today",
+            sessions: []shared.SessionRecord{ lesson, lesson },
+            streak: Streak{ Current: 3, Best: 3, LastDay: today },
+            want: Streak{ Current: 3, Best: 3, LastDay: today },
+        },
+    }
+
+    previousHistory, previousGoals := history, gameCtx.Settings.Goals
+    t.Cleanup(func() {
+        history = previousHistory
+        gameCtx.Settings.Goals = previousGoals
+    })
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            history = History{ Sessions: test.sessions, Streak: test.streak }
+            gameCtx.Settings.Goals = test.goals
+
+            updateStreak(now)
+            if history.Streak != test.want {
+                t.Errorf("streak %+v, want %+v", history.Streak, test.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,110 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// History stores the results of every finished lesson.
+type History struct {
+    Sessions    []shared.SessionRecord          `json:"sessions"`   // The finished lessons, oldest first
+    Words       map[string]shared.WordStats     `json:"words"`      // How well the player types each word over every lesson
+    Streak      Streak                          `json:"streak"`     // The days in a row the daily goals have been met
+}
+
+
+var history History
+
+
+// loadHistory loads the lesson history from the history file. If no file
+// exists an empty history is used.
+func loadHistory() error {
+    history = History{}
+    if _, err := os.Stat(savePath("history")); errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+
+    saveData, err := os.ReadFile(savePath("history"))
+    if err != nil {
+        return err
+    }
+
+    return json.Unmarshal(saveData, &history)
+}
+
+
+// saveHistory writes the lesson history to the history file.
+func saveHistory() error {
+    b, err := json.Marshal(history)
+    if err != nil {
+        return err
+    }
+
+    return os.WriteFile(savePath("history"), b, 0644)
+}
+
+
+// newSessionRecord creates the record of a finished lesson given the seed
+// its words were generated from, the amount of correct and incorrect
+// keystrokes and the time from the first to the last keystroke in
+// milliseconds.
+func newSessionRecord(words string, seed int64, correct, incorrect int, duration int64) shared.SessionRecord {
+    record := shared.SessionRecord{
+        Time: time.Now().UnixMilli(),
+        Words: words,
+        Seed: seed,
+        Correct: correct,
+        Incorrect: incorrect,
+        Duration: duration,
+    }
+
+    if duration > 0 {
+        record.CPM = float64(utf8.RuneCountInString(words) - 1) * 60000 / float64(duration)
+    }
+    if correct + incorrect > 0 {
+        record.Accuracy = float64(correct) / float64(correct + incorrect)
+    }
+
+    return record
+}
+
+
+// recordSession adds a record to the history, extends the streak if the
+// lesson meets the daily goals and saves it.
+func recordSession(record shared.SessionRecord) error {
+    history.Sessions = append(history.Sessions, record)
+    updateStreak(time.UnixMilli(record.Time))
+
+    return saveHistory()
+}
+
+
+// recordLesson adds the lesson the player just finished to the history and
+// adds the achievements it earned to the report of the lesson.
+func recordLesson() error {
+    duration := gameCtx.KeyTimes[len(gameCtx.Chars)-2]
+
+    record := newSessionRecord(gameCtx.Words, gameCtx.Seed, gameCtx.Correct, gameCtx.Incorrect, duration)
+    record.Source = lessonSource(gameCtx.Seed)
+    record.FirstTryErrors, record.Corrected = gameCtx.Corrections.stats()
+    record.Keystrokes = gameCtx.Typed
+    recordWordStats(gameCtx.Words, &gameCtx.Corrections)
+
+    if err := recordSession(record); err != nil {
+        return err
+    }
+
+    earned, err := recordAchievements(record)
+    if err != nil {
+        return fmt.Errorf("saving achievements: %w", err)
+    }
+    gameCtx.Report.Achievements = earned
+
+    return nil
+}
@@ -4,63 +4,138 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
 	"github.com/gdamore/tcell/v2"
 )
 
 // gameInputHandler handles the input from the user when the game is running.
 // It checks if the user inputs the correct character according to
-// the current character index. When the user reaches the end of the words
-// it signals to change the current input capture function to endScreenLogic.
+// the current character index. Whether the cursor moves on after an error
+// and whether backspace may be used depends on the error policy. When the
+// user reaches the end of the words it signals to change the current input
+// capture function to endScreenLogic.
 func gameInputHandler(event *tcell.EventKey) *tcell.EventKey {
     if event.Key() == tcell.KeyEscape {
         graphicsCtx.App.Stop()
     }
 
     if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
-        graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = "white"
+        if !gameCtx.Corrections.canBackspace() {
+            return nil
+        }
+
+        graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = graphics.CharUntyped
 
         gameCtx.CurrentCharIndex -= 1
         if gameCtx.CurrentCharIndex < 0 { gameCtx.CurrentCharIndex = 0 }
 
         graphicsCtx.MainTextView.Highlight(fmt.Sprintf("%d", gameCtx.CurrentCharIndex))
-        graphicsCtx.DrawText(gameCtx.Words, gameCtx.PriorityCharacter, gameCtx.CurrentChars, gameCtx.CharacterAccuracies)
+        drawGame()
+        updateRaceProgress()
         return event
     }
 
-    if event.Rune() == rune(gameCtx.Words[gameCtx.CurrentCharIndex]) {
-        updateAccuracy(rune(gameCtx.Words[gameCtx.CurrentCharIndex]), true)
+    if gameCtx.LessonStartTime == 0 {
+        gameCtx.LessonStartTime = time.Now().UnixMilli()
+    }
 
-        if !gameCtx.Started {
-            gameCtx.Started = true
-            gameCtx.StartTimeCharacter = time.Now().UnixMilli()
-        } else {
-            ca := gameCtx.CharacterAccuracies[rune(gameCtx.Words[gameCtx.CurrentCharIndex])]
-            ca.TotalTime += time.Now().UnixMilli() - gameCtx.StartTimeCharacter
-            ca.AverageTime = ca.TotalTime / ca.Attempts
+    expected := gameCtx.Chars[gameCtx.CurrentCharIndex]
+    elapsed := int64(-1)
+    if gameCtx.Started {
+        elapsed = time.Now().UnixMilli() - gameCtx.StartTimeCharacter
+    }
+
+    success := event.Rune() == expected
+    gameCtx.Typed = append(gameCtx.Typed, shared.KeystrokeRecord{
+        Expected: string(expected),
+        Typed: string(event.Rune()),
+        Retry: gameCtx.Corrections.mistyped(gameCtx.CurrentCharIndex),
+    })
+    firstTry, advance := gameCtx.Corrections.keystroke(gameCtx.CurrentCharIndex, success)
+
+    // Every wrong keystroke counts towards the mistyped keys, as retries
+    // often repeat the same substitution
+    if !success {
+        recordConfusion(expected, event.Rune())
+    }
+
+    // Only the first keystroke on a character counts towards its accuracy
+    if firstTry {
+        keystroke := recordKeystroke(expected, event.Rune(), elapsed)
+        gameCtx.Keystrokes = append(gameCtx.Keystrokes, keystroke)
+    }
 
-            gameCtx.CharacterAccuracies[rune(gameCtx.Words[gameCtx.CurrentCharIndex])] = ca
+    if success {
+        gameCtx.Started = true
+        gameCtx.Streak++
+        if gameCtx.Streak > gameCtx.BestStreak {
+            gameCtx.BestStreak = gameCtx.Streak
         }
 
-        graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = "blue"
+        // Corrected characters are shown in their own color
+        if firstTry {
+            graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = graphics.CharCorrect
+        } else {
+            graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = graphics.CharCorrected
+        }
         gameCtx.Correct += 1
     } else {
-        updateAccuracy(rune(gameCtx.Words[gameCtx.CurrentCharIndex]), false)
-        graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = "red"
+        graphicsCtx.MainColorMap[gameCtx.CurrentCharIndex] = graphics.CharIncorrect
         gameCtx.Incorrect += 1
+        gameCtx.Streak = 0
+    }
+
+    if !advance {
+        drawGame()
+        updateRaceProgress()
+        return event
     }
 
-    graphicsCtx.DrawText(gameCtx.Words, gameCtx.PriorityCharacter, gameCtx.CurrentChars, gameCtx.CharacterAccuracies)
+    gameCtx.KeyTimes[gameCtx.CurrentCharIndex] = time.Now().UnixMilli() - gameCtx.LessonStartTime
+    gameCtx.Corrections.pass(gameCtx.CurrentCharIndex, gameCtx.KeyTimes[gameCtx.CurrentCharIndex])
+    updateGhost()
+    drawGame()
 
     gameCtx.CurrentCharIndex += 1
-    if gameCtx.CurrentCharIndex >= len(gameCtx.Words) - 1 {
-        graphicsCtx.ShowEndScreen(float64(gameCtx.Correct), float64(gameCtx.Incorrect))
+    if gameCtx.CurrentCharIndex >= len(gameCtx.Chars) - 1 {
+        gameCtx.Finished = true
+        gameCtx.Report = lessonReport()
+
+        // The lesson is recorded before the end screen is shown so that the
+        // achievements it earned and the course result are on it
+        historyErr := recordLesson()
+        var courseErr error
+        gameCtx.Report.Course, courseErr = recordCourseLesson(gameCtx.Report.WPM, gameCtx.Report.Accuracy)
+        if gameCtx.Race != nil {
+            finishRace()
+        } else {
+            showEndScreen()
+            inputCaptureChangeChan <- endScreenInputHandler
+        }
+
         SaveCharacterAccuracies()
-        inputCaptureChangeChan <- endScreenInputHandler
+        if historyErr != nil {
+            graphicsCtx.ShowErrorScreen("saving lesson history", historyErr)
+        }
+        if courseErr != nil {
+            graphicsCtx.ShowErrorScreen("saving course progress", courseErr)
+        }
+        if err := saveConfusions(); err != nil {
+            graphicsCtx.ShowErrorScreen("saving mistyped keys", err)
+        }
+        if err := recordGhostRun(); err != nil {
+            graphicsCtx.ShowErrorScreen("saving ghost run", err)
+        }
+        if err := reviewLesson(gameCtx.Keystrokes); err != nil {
+            graphicsCtx.ShowErrorScreen("saving review schedule", err)
+        }
         return event
     }
 
     graphicsCtx.MainTextView.Highlight(fmt.Sprintf("%d", gameCtx.CurrentCharIndex))
     gameCtx.StartTimeCharacter = time.Now().UnixMilli()
+    updateRaceProgress()
 
     return event
 }
@@ -70,7 +145,11 @@ func gameInputHandler(event *tcell.EventKey) *tcell.EventKey {
 // From here the player is able to either start a new game with the
 // <Enter> key or stop the game using <Escape>. If <Enter> is pressed
 // the game context will be reset and the input capture function will
-// transition to gameLogic
+// transition to gameLogic. The player may also retry the same lesson
+// with <2>, cycle through the ghost modes with <3>, show the finger
+// stats with <4>, practice the words they have the most trouble with
+// using <5>, cycle through the built-in themes with <6>, show the keys
+// they mistype most with <7> or show their achievements with <8>.
 func endScreenInputHandler(event *tcell.EventKey) *tcell.EventKey {
     if event.Key() == tcell.KeyEnter {
         newGame()
@@ -82,6 +161,31 @@ func endScreenInputHandler(event *tcell.EventKey) *tcell.EventKey {
         graphicsCtx.ShowConfirmDeleteSaveScreen()
         inputCaptureChangeChan <- clearSaveInputHandler
         return nil
+    } else if event.Rune() == '2' {
+        retryLesson()
+        return nil
+    } else if event.Rune() == '3' {
+        gameCtx.Settings.GhostMode = nextGhostMode(gameCtx.Settings.GhostMode)
+        showEndScreen()
+        return nil
+    } else if event.Rune() == '4' {
+        showFingerStats()
+        inputCaptureChangeChan <- fingerStatsInputHandler
+        return nil
+    } else if event.Rune() == '5' {
+        newProblemWordsGame()
+        return nil
+    } else if event.Rune() == '6' {
+        switchTheme(graphics.NextTheme(gameCtx.Theme.Name))
+        return nil
+    } else if event.Rune() == '7' {
+        showConfusions()
+        inputCaptureChangeChan <- confusionsInputHandler
+        return nil
+    } else if event.Rune() == '8' {
+        showAchievements()
+        inputCaptureChangeChan <- achievementsInputHandler
+        return nil
     }
 
     return event
@@ -93,7 +197,7 @@ func endScreenInputHandler(event *tcell.EventKey) *tcell.EventKey {
 // the end screen
 func clearSaveInputHandler(event *tcell.EventKey) *tcell.EventKey {
     if event.Rune() == '1' {
-        graphicsCtx.ShowEndScreen(float64(gameCtx.Correct), float64(gameCtx.Incorrect))
+        showEndScreen()
         inputCaptureChangeChan <- endScreenInputHandler
         return nil
     } else if event.Rune() == '2' {
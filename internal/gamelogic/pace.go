@@ -0,0 +1,74 @@
+package gamelogic
+
+import (
+	"time"
+)
+
+// metronomeIdleInterval is how often the metronome checks if a lesson has
+// started.
+const metronomeIdleInterval = 50 * time.Millisecond
+
+
+// beatAt returns the amount of characters typed at the target CPM after the
+// given time in milliseconds, which is also the index of the current beat.
+func beatAt(elapsed int64) int {
+    return int(elapsed * int64(gameCtx.Settings.TargetCPM) / 60000)
+}
+
+
+// beatTime returns the time of the given beat in milliseconds, rounded up
+// so that the beat has been reached at that time.
+func beatTime(beat int) int64 {
+    cpm := int64(gameCtx.Settings.TargetCPM)
+    return (int64(beat) * 60000 + cpm - 1) / cpm
+}
+
+
+// updatePace moves the pace caret to the character a player typing at the
+// target CPM would be on, and updates how far ahead of it the player is.
+// The caret waits on the first character until the lesson starts.
+func updatePace() {
+    if !gameCtx.Settings.PaceCaret {
+        graphicsCtx.PaceIndex = -1
+        return
+    }
+
+    paceIndex := 0
+    if gameCtx.LessonStartTime != 0 {
+        elapsed := time.Now().UnixMilli() - gameCtx.LessonStartTime
+        paceIndex = beatAt(elapsed)
+    }
+    if last := len(gameCtx.Chars) - 2; paceIndex > last {
+        paceIndex = last
+    }
+
+    graphicsCtx.PaceIndex = paceIndex
+    graphicsCtx.PaceLead = gameCtx.CurrentCharIndex - paceIndex
+}
+
+
+// runMetronome rings the terminal bell on every beat of the target CPM
+// while a lesson is in progress, starting at the first keystroke. The game
+// context is only read on the event loop, which decides how long to wait
+// for the next beat.
+func runMetronome() {
+    for {
+        wait := metronomeIdleInterval
+        graphicsCtx.App.QueueUpdate(func() {
+            if gameCtx.LessonStartTime == 0 || gameCtx.Finished {
+                return
+            }
+
+            elapsed := time.Now().UnixMilli() - gameCtx.LessonStartTime
+            beat := beatAt(elapsed)
+            if beat > gameCtx.Beat {
+                gameCtx.Beat = beat
+                graphicsCtx.Beep()
+            }
+
+            wait = time.Duration(beatTime(beat + 1) - elapsed) * time.Millisecond
+        })
+
+        time.Sleep(wait)
+    }
+}
@@ -0,0 +1,80 @@
+package gamelogic
+
+import "sort"
+
+// minPriorityWeight is the lowest weight a priority character is given, so
+// that even well known priority characters show up in the words.
+const minPriorityWeight = 0.05
+
+
+// getPriorityCharacters returns up to n of the current characters to target
+// in the next lesson, weakest first, according to the scheduler in the
+// settings. With the srs scheduler the due characters are chosen first and
+// the remaining spots are filled by the characters with the lowest scores.
+func getPriorityCharacters(n int) []rune {
+    if n > len(gameCtx.CurrentChars) {
+        n = len(gameCtx.CurrentChars)
+    }
+
+    priorityChars := make([]rune, 0, n)
+    chosen := make(map[rune]bool)
+    if gameCtx.Settings.Scheduler == SchedulerSRS {
+        for _, char := range getDueCharacters(n) {
+            chosen[char] = true
+            priorityChars = append(priorityChars, char)
+        }
+    }
+
+    for _, char := range getLowestScoreCharacters() {
+        if len(priorityChars) == n {
+            break
+        }
+
+        if !chosen[char] {
+            chosen[char] = true
+            priorityChars = append(priorityChars, char)
+        }
+    }
+
+    return priorityChars
+}
+
+
+// getLowestScoreCharacters returns the current characters sorted by their
+// score, lowest first. Characters which have not been attempted come first.
+func getLowestScoreCharacters() []rune {
+    chars := make([]rune, 0, len(gameCtx.CurrentChars))
+    for _, char := range gameCtx.CurrentChars {
+        if char != ' ' {
+            chars = append(chars, char)
+        }
+    }
+
+    sort.SliceStable(chars, func(i, j int) bool {
+        return gameCtx.CharacterAccuracies[chars[i]].Score < gameCtx.CharacterAccuracies[chars[j]].Score
+    })
+
+    return chars
+}
+
+
+// priorityWeights returns the weight of each priority character used when
+// picking words. Weaker characters are given a higher weight.
+func priorityWeights(priorityChars []rune) map[rune]float64 {
+    weights := make(map[rune]float64, len(priorityChars))
+    for _, char := range priorityChars {
+        weight := 1.0
+        if score := gameCtx.CharacterAccuracies[char].Score; score != -1 {
+            weight = 1 - score
+        }
+
+        if weight < minPriorityWeight {
+            weight = minPriorityWeight
+        } else if weight > 1 {
+            weight = 1
+        }
+        weights[char] = weight
+    }
+
+    return weights
+}
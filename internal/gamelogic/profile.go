@@ -0,0 +1,40 @@
+package gamelogic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profilesDir is the directory the save files of named profiles are kept in.
+const profilesDir = "profiles"
+
+// saveDir is the directory of the save files of the profile in use. The
+// default profile keeps its save files in the working directory.
+var saveDir = "."
+
+
+// SetProfile makes the game read and write the save files and settings of
+// the profile with the given name, kept in profiles/<name>. The directory is
+// created if it does not exist. Must be called before the game is started.
+func SetProfile(name string) error {
+    if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+        return fmt.Errorf("invalid profile name %q", name)
+    }
+
+    dir := filepath.Join(profilesDir, name)
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+    saveDir = dir
+
+    return nil
+}
+
+
+// savePath returns the path of the save file with the given name in the
+// profile in use.
+func savePath(name string) string {
+    return filepath.Join(saveDir, name)
+}
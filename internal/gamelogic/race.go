@@ -0,0 +1,210 @@
+package gamelogic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/multiplayer"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+	"github.com/gdamore/tcell/v2"
+)
+
+// RaceSession stores the state of a multiplayer race the player is part of.
+type RaceSession struct {
+    Participant multiplayer.Participant             // The player's side of the race
+    Host        *multiplayer.Host                   // The hosted race, nil if the player joined another host
+    Players     []shared.RaceProgress               // The progress of every participant in the race
+    InLobby     bool                                // InLobby is true until the first race starts
+}
+
+
+// StartHostGame starts the game as the host of a race on the local network.
+// Players may join on the given address until the host starts the race.
+func StartHostGame(addr, name string) error {
+    if err := initGame(); err != nil {
+        return err
+    }
+
+    host, err := multiplayer.NewHost(addr, name)
+    if err != nil {
+        return err
+    }
+    defer host.Close()
+
+    initGraphics()
+    gameCtx.Race = &RaceSession{
+        Participant: host,
+        Host: host,
+        Players: host.Players(),
+        InLobby: true,
+    }
+
+    // The host notifies about updates from within the input handlers when the
+    // hosting player makes progress, so the update is queued from a goroutine
+    // to avoid blocking the event loop. The latest state is read when the
+    // update runs as queued updates may run out of order.
+    host.OnUpdate = func([]shared.RaceProgress) {
+        go graphicsCtx.App.QueueUpdateDraw(func() {
+            onRaceUpdate(host.Players())
+        })
+    }
+    go host.Serve()
+
+    graphicsCtx.ShowRaceLobby(gameCtx.Race.Players, host.Addr().String(), true)
+    graphicsCtx.App.SetInputCapture(raceInputHandler)
+
+    return runApp()
+}
+
+
+// StartJoinGame starts the game as a participant in the race hosted on the
+// given address.
+func StartJoinGame(addr, name string) error {
+    if err := initGame(); err != nil {
+        return err
+    }
+
+    client, err := multiplayer.Join(addr, name)
+    if err != nil {
+        return err
+    }
+    defer client.Close()
+
+    initGraphics()
+    gameCtx.Race = &RaceSession{
+        Participant: client,
+        InLobby: true,
+    }
+
+    client.OnLesson = func(lesson multiplayer.Lesson) {
+        graphicsCtx.App.QueueUpdateDraw(func() {
+            gameCtx.CurrentChars = []rune(lesson.Characters)
+            gameCtx.PriorityCharacters = []rune(lesson.Targets)
+            setSource(LessonRace, 0, 0)
+            startRaceLesson(lesson.Words, lesson.Seed)
+        })
+    }
+    client.OnUpdate = func(players []shared.RaceProgress) {
+        graphicsCtx.App.QueueUpdateDraw(func() {
+            onRaceUpdate(players)
+        })
+    }
+    client.OnClose = func(err error) {
+        graphicsCtx.App.QueueUpdateDraw(func() {
+            graphicsCtx.ShowErrorScreen("racing", fmt.Errorf("lost connection to the host: %w", err))
+            inputCaptureChangeChan <- raceInputHandler
+        })
+    }
+    go client.Listen()
+
+    graphicsCtx.ShowRaceLobby(gameCtx.Race.Players, addr, false)
+    graphicsCtx.App.SetInputCapture(raceInputHandler)
+
+    return runApp()
+}
+
+
+// startRaceLesson starts a lesson with the words of the race and the seed
+// they were generated from. The current and priority characters must be set
+// to the ones of the race beforehand.
+func startRaceLesson(words string, seed int64) {
+    gameCtx.Race.InLobby = false
+    gameCtx.Seed = seed
+    startLesson(words)
+}
+
+
+// newRace generates new words and starts a race with them. Only the host
+// may start races.
+func newRace() {
+    words, err := generateWords()
+    if err != nil {
+        graphicsCtx.ShowErrorScreen("generating new words", err)
+        inputCaptureChangeChan <- raceInputHandler
+        return
+    }
+
+    gameCtx.Race.Host.StartRace(multiplayer.Lesson{
+        Words: words,
+        Seed: gameCtx.Seed,
+        Characters: string(gameCtx.CurrentChars),
+        Targets: string(gameCtx.PriorityCharacters),
+    })
+    startRaceLesson(words, gameCtx.Seed)
+}
+
+
+// onRaceUpdate stores the progress of the participants and redraws the
+// screen currently shown.
+func onRaceUpdate(players []shared.RaceProgress) {
+    gameCtx.Race.Players = players
+    graphicsCtx.RacePlayers = players
+
+    switch {
+    case gameCtx.Race.InLobby:
+        addr := ""
+        if gameCtx.Race.Host != nil {
+            addr = gameCtx.Race.Host.Addr().String()
+        }
+        graphicsCtx.ShowRaceLobby(players, addr, gameCtx.Race.Host != nil)
+    case gameCtx.Finished:
+        graphicsCtx.ShowRaceResults(players, gameCtx.Race.Host != nil)
+    default:
+        drawGame()
+    }
+}
+
+
+// updateRaceProgress reports the progress of the player to the other
+// participants. Does nothing when the player is not in a race.
+func updateRaceProgress() {
+    if gameCtx.Race == nil {
+        return
+    }
+
+    progress := shared.RaceProgress{
+        Index: gameCtx.CurrentCharIndex,
+        Total: len(gameCtx.Chars) - 1,
+        Correct: gameCtx.Correct,
+        Incorrect: gameCtx.Incorrect,
+        Finished: gameCtx.Finished,
+    }
+
+    if gameCtx.Finished {
+        progress.Time = gameCtx.KeyTimes[len(gameCtx.Chars)-2]
+    } else if gameCtx.LessonStartTime != 0 {
+        progress.Time = time.Now().UnixMilli() - gameCtx.LessonStartTime
+    }
+
+    if err := gameCtx.Race.Participant.UpdateProgress(progress); err != nil {
+        graphicsCtx.ShowErrorScreen("sending race progress", err)
+    }
+}
+
+
+// finishRace reports that the player has finished the race and shows the
+// results table, which is updated as the other participants finish.
+func finishRace() {
+    updateRaceProgress()
+    graphicsCtx.ShowRaceResults(gameCtx.Race.Players, gameCtx.Race.Host != nil)
+    inputCaptureChangeChan <- raceInputHandler
+}
+
+
+// raceInputHandler handles the input in the race lobby and on the race
+// results screen. The host starts the next race with <Enter>, and any player
+// may leave with <Escape>. Joined players are moved into the race when the
+// host starts it.
+func raceInputHandler(event *tcell.EventKey) *tcell.EventKey {
+    if event.Key() == tcell.KeyEscape {
+        graphicsCtx.App.Stop()
+        return nil
+    }
+
+    if event.Key() == tcell.KeyEnter && gameCtx.Race.Host != nil {
+        newRace()
+        return nil
+    }
+
+    return event
+}
@@ -0,0 +1,95 @@
+package gamelogic
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// The types of lessons which are not generated from their characters and
+// targets, and are typed again as they were when replayed.
+const (
+    LessonText      = "text"    // A fixed text of a course stage
+    LessonRace      = "race"    // Words generated by the host of a race
+)
+
+
+// replaySeed is the seed of the lesson in the history played as the first
+// lesson, 0 to generate a new one.
+var replaySeed int64
+
+
+// SetReplay sets the seed of a lesson in the history to play again as the
+// first lesson. Must be called before the game is started.
+func SetReplay(seed int64) {
+    replaySeed = seed
+}
+
+
+// setSource stores what the words of the current lesson are generated from,
+// given the type of the lesson and the bounds of the word lengths. The
+// current and priority characters must be set beforehand.
+func setSource(lessonType string, minWordLength, maxWordLength int) {
+    gameCtx.Source = shared.LessonSource{
+        Type: lessonType,
+        Characters: string(gameCtx.CurrentChars),
+        Targets: string(gameCtx.PriorityCharacters),
+        MinWordLength: minWordLength,
+        MaxWordLength: maxWordLength,
+    }
+}
+
+
+// lessonSource returns what the words of a lesson generated from the given
+// seed were generated from, nil if they were not generated by this game.
+func lessonSource(seed int64) *shared.LessonSource {
+    if seed == 0 || seed != gameCtx.Seed {
+        return nil
+    }
+
+    source := gameCtx.Source
+    return &source
+}
+
+
+// replayLesson returns the words of the latest lesson in the history with
+// the given seed and makes its characters the current ones. Generated
+// lessons are generated again from their seed, characters and targets, and
+// other lessons are typed again as they were.
+func replayLesson(seed int64) (string, error) {
+    for i := len(history.Sessions) - 1; i >= 0; i-- {
+        record := history.Sessions[i]
+        if record.Seed != seed {
+            continue
+        }
+
+        if record.Source == nil {
+            return "", fmt.Errorf("the lesson with seed %d was recorded without the characters it was generated from", seed)
+        }
+
+        gameCtx.CurrentChars = []rune(record.Source.Characters)
+        gameCtx.PriorityCharacters = []rune(record.Source.Targets)
+        gameCtx.Seed = seed
+        gameCtx.Source = *record.Source
+        gameCtx.CourseStage = -1
+
+        if record.Source.Type != "" {
+            return record.Words, nil
+        }
+
+        wordsList := gameCtx.Dictionary.WordsFromTargets(
+            rand.New(rand.NewSource(seed)),
+            gameCtx.CurrentChars,
+            priorityWeights(gameCtx.PriorityCharacters),
+            record.Source.MinWordLength,
+            record.Source.MaxWordLength,
+            len(strings.Fields(record.Words)),
+        )
+
+        return joinWords(wordsList), nil
+    }
+
+    return "", fmt.Errorf("no lesson with seed %d in the history", seed)
+}
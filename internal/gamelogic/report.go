@@ -0,0 +1,182 @@
+package gamelogic
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+)
+
+// reportKeys is the amount of slowest and most missed keys in the report of
+// a lesson.
+const reportKeys = 3
+
+// reportAverageLessons is the amount of earlier lessons the report of a
+// lesson is compared to.
+const reportAverageLessons = 10
+
+
+// lessonReport returns the report of the lesson which was just finished.
+// Must be called before the lesson is added to the history, so that the
+// lesson is compared to the lessons before it.
+func lessonReport() graphics.LessonReport {
+    var report graphics.LessonReport
+
+    if duration := gameCtx.KeyTimes[len(gameCtx.Chars)-2]; duration > 0 {
+        report.WPM = float64(len(gameCtx.Chars) - 1) / 5 * 60000 / float64(duration)
+    }
+    if gameCtx.Correct + gameCtx.Incorrect > 0 {
+        report.Accuracy = float64(gameCtx.Correct) / float64(gameCtx.Correct + gameCtx.Incorrect)
+    }
+    report.FirstTryErrors, report.Corrected = gameCtx.Corrections.stats()
+
+    report.MeanTime, report.Consistency = keystrokeTimeSpread(gameCtx.Keystrokes)
+    report.Slowest = slowestKeys(gameCtx.Keystrokes, reportKeys)
+    report.Missed = missedKeys(gameCtx.Keystrokes, reportKeys)
+
+    for _, char := range gameCtx.CurrentChars {
+        if !attempted(gameCtx.Keystrokes, char) {
+            continue
+        }
+
+        report.Deltas = append(report.Deltas, graphics.ScoreDelta{
+            Char: char,
+            Before: gameCtx.StartScores[char],
+            After: gameCtx.CharacterAccuracies[char].Score,
+        })
+    }
+
+    sessions := history.Sessions
+    if len(sessions) > reportAverageLessons {
+        sessions = sessions[len(sessions)-reportAverageLessons:]
+    }
+    for _, session := range sessions {
+        report.AverageWPM += session.CPM / 5
+        report.AverageAccuracy += session.Accuracy
+    }
+    if len(sessions) > 0 {
+        report.AverageLessons = len(sessions)
+        report.AverageWPM /= float64(len(sessions))
+        report.AverageAccuracy /= float64(len(sessions))
+    }
+
+    return report
+}
+
+
+// keystrokeTimeSpread returns the mean and the standard deviation of the
+// time of the timed keystrokes in milliseconds.
+func keystrokeTimeSpread(keystrokes []keystrokeRecord) (float64, float64) {
+    var times []float64
+    for _, keystroke := range keystrokes {
+        if keystroke.Time >= 0 {
+            times = append(times, float64(keystroke.Time))
+        }
+    }
+    if len(times) == 0 {
+        return 0, 0
+    }
+
+    mean := 0.0
+    for _, t := range times {
+        mean += t
+    }
+    mean /= float64(len(times))
+
+    variance := 0.0
+    for _, t := range times {
+        variance += (t - mean) * (t - mean)
+    }
+    variance /= float64(len(times))
+
+    return mean, math.Sqrt(variance)
+}
+
+
+// slowestKeys returns the keys with the highest average time over the timed
+// keystrokes, slowest first and ties by the character.
+func slowestKeys(keystrokes []keystrokeRecord, amount int) []graphics.KeyTime {
+    totals := make(map[rune]int64)
+    counts := make(map[rune]int64)
+    for _, keystroke := range keystrokes {
+        if keystroke.Time >= 0 {
+            totals[keystroke.Expected] += keystroke.Time
+            counts[keystroke.Expected]++
+        }
+    }
+
+    keys := make([]graphics.KeyTime, 0, len(totals))
+    for char, total := range totals {
+        keys = append(keys, graphics.KeyTime{ Char: char, AverageTime: total / counts[char] })
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        if keys[i].AverageTime != keys[j].AverageTime {
+            return keys[i].AverageTime > keys[j].AverageTime
+        }
+        return keys[i].Char < keys[j].Char
+    })
+
+    if len(keys) > amount {
+        keys = keys[:amount]
+    }
+
+    return keys
+}
+
+
+// missedKeys returns the keys typed wrong most often together with what was
+// typed instead, most missed first and ties by the character.
+func missedKeys(keystrokes []keystrokeRecord, amount int) []graphics.MissedKey {
+    substitutions := make(map[rune]map[rune]int)
+    for _, keystroke := range keystrokes {
+        if keystroke.Correct {
+            continue
+        }
+
+        if substitutions[keystroke.Expected] == nil {
+            substitutions[keystroke.Expected] = make(map[rune]int)
+        }
+        substitutions[keystroke.Expected][keystroke.Typed]++
+    }
+
+    keys := make([]graphics.MissedKey, 0, len(substitutions))
+    for char, typed := range substitutions {
+        key := graphics.MissedKey{ Char: char }
+        for typedChar, count := range typed {
+            key.Count += count
+            key.Typed = append(key.Typed, graphics.Substitution{ Char: typedChar, Count: count })
+        }
+        sort.Slice(key.Typed, func(i, j int) bool {
+            if key.Typed[i].Count != key.Typed[j].Count {
+                return key.Typed[i].Count > key.Typed[j].Count
+            }
+            return key.Typed[i].Char < key.Typed[j].Char
+        })
+
+        keys = append(keys, key)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        if keys[i].Count != keys[j].Count {
+            return keys[i].Count > keys[j].Count
+        }
+        return keys[i].Char < keys[j].Char
+    })
+
+    if len(keys) > amount {
+        keys = keys[:amount]
+    }
+
+    return keys
+}
+
+
+// attempted returns true if the character was attempted in the keystrokes.
+func attempted(keystrokes []keystrokeRecord, char rune) bool {
+    for _, keystroke := range keystrokes {
+        if keystroke.Expected == char {
+            return true
+        }
+    }
+
+    return false
+}
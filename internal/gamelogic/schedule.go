@@ -0,0 +1,119 @@
+package gamelogic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/srs"
+)
+
+// Schedulers selectable in GameSettings.Scheduler.
+const (
+    SchedulerScore  = "score"   // The characters with the lowest scores are the priority characters
+    SchedulerSRS    = "srs"     // The most overdue characters and bigrams in the review schedule decide the priority characters
+)
+
+
+// schedule is the review schedule of the characters and bigrams, saved in the
+// accuracies file.
+var schedule srs.Schedule
+
+
+// deleteSchedule clears the review schedule. It is removed from the disk
+// together with the accuracies file.
+func deleteSchedule() {
+    schedule = make(srs.Schedule)
+}
+
+
+// itemStats counts the attempts at a character or bigram within a lesson.
+type itemStats struct {
+    attempts    int
+    correct     int
+    totalTime   int64
+    timed       int
+}
+
+
+// reviewLesson reviews every character and bigram typed in the lesson with
+// a quality given by the accuracy and speed they were typed with, then saves
+// the schedule with the accuracies. The time of a bigram is the time of its second keystroke.
+func reviewLesson(keystrokes []keystrokeRecord) error {
+    stats := make(map[string]*itemStats)
+    add := func(key string, keystroke keystrokeRecord) {
+        if stats[key] == nil {
+            stats[key] = &itemStats{}
+        }
+
+        stats[key].attempts++
+        if keystroke.Correct {
+            stats[key].correct++
+            if keystroke.Time >= 0 {
+                stats[key].totalTime += keystroke.Time
+                stats[key].timed++
+            }
+        }
+    }
+
+    for i, keystroke := range keystrokes {
+        if keystroke.Expected == ' ' {
+            continue
+        }
+        add(string(keystroke.Expected), keystroke)
+
+        if i > 0 && keystrokes[i-1].Expected != ' ' && keystrokes[i-1].Correct {
+            add(string([]rune{ keystrokes[i-1].Expected, keystroke.Expected }), keystroke)
+        }
+    }
+
+    targetTime := float64(60000 / gameCtx.Settings.TargetCPM)
+    now := time.Now()
+    for key, item := range stats {
+        accuracy := float64(item.correct) / float64(item.attempts)
+        averageTime := 0.0
+        if item.timed > 0 {
+            averageTime = float64(item.totalTime) / float64(item.timed)
+        }
+
+        schedule.Review(key, srs.Quality(accuracy, averageTime, targetTime), now)
+    }
+
+    return SaveCharacterAccuracies()
+}
+
+
+// getDueCharacters returns up to n priority characters chosen from the due
+// items of the review schedule, most overdue first. Only characters and
+// bigrams made up of the current characters are considered, and both
+// characters of a due bigram are chosen.
+func getDueCharacters(n int) []rune {
+    current := make(map[rune]bool)
+    for _, char := range gameCtx.CurrentChars {
+        current[char] = true
+    }
+
+    candidates := make([]string, 0)
+    for _, char := range gameCtx.CurrentChars {
+        candidates = append(candidates, string(char))
+    }
+    for key := range schedule {
+        chars := []rune(key)
+        if len(chars) == 2 && current[chars[0]] && current[chars[1]] {
+            candidates = append(candidates, key)
+        }
+    }
+    sort.Strings(candidates)
+
+    chosen := make(map[rune]bool)
+    priorityChars := make([]rune, 0, n)
+    for _, key := range schedule.Due(candidates, time.Now()) {
+        for _, char := range key {
+            if len(priorityChars) < n && !chosen[char] {
+                chosen[char] = true
+                priorityChars = append(priorityChars, char)
+            }
+        }
+    }
+
+    return priorityChars
+}
@@ -0,0 +1,76 @@
+package gamelogic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/graphics"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// sessionStats returns the live stats of the lesson in progress. The
+// current characters are listed weakest first by their lifetime score,
+// characters which were never attempted first and ties by the character,
+// so the order stays the same between redraws.
+func sessionStats() graphics.SessionStats {
+    stats := graphics.SessionStats{
+        Accuracy: -1,
+        Streak: gameCtx.Streak,
+        BestStreak: gameCtx.BestStreak,
+        Remaining: len(gameCtx.Chars) - 1 - gameCtx.CurrentCharIndex,
+    }
+
+    if gameCtx.Correct + gameCtx.Incorrect > 0 {
+        stats.Accuracy = float64(gameCtx.Correct) / float64(gameCtx.Correct + gameCtx.Incorrect)
+    }
+
+    if gameCtx.LessonStartTime != 0 {
+        stats.Elapsed = time.Now().UnixMilli() - gameCtx.LessonStartTime
+    }
+    if stats.Elapsed > 0 {
+        stats.WPM = float64(gameCtx.CurrentCharIndex) / 5 * 60000 / float64(stats.Elapsed)
+    }
+
+    session := sessionAccuracies()
+    for _, char := range gameCtx.CurrentChars {
+        ca := gameCtx.CharacterAccuracies[char]
+        charStats := graphics.CharStats{
+            Char: char,
+            Lifetime: -1,
+            Session: -1,
+            AverageTime: ca.AverageTime,
+        }
+        if ca.Attempts > 0 {
+            charStats.Lifetime = ca.Score
+        }
+        if sessionCa, ok := session[char]; ok {
+            charStats.Session = sessionCa.Score
+        }
+
+        stats.Chars = append(stats.Chars, charStats)
+    }
+
+    sort.Slice(stats.Chars, func(i, j int) bool {
+        if stats.Chars[i].Lifetime != stats.Chars[j].Lifetime {
+            return stats.Chars[i].Lifetime < stats.Chars[j].Lifetime
+        }
+        return stats.Chars[i].Char < stats.Chars[j].Char
+    })
+
+    return stats
+}
+
+
+// sessionAccuracies returns the accuracy of each character over the first
+// keystrokes on it in the lesson in progress, scored with the scoring
+// strategy in use.
+func sessionAccuracies() map[rune]shared.CharacterAccuracy {
+    accuracies := make(map[rune]shared.CharacterAccuracy)
+    for _, keystroke := range gameCtx.Keystrokes {
+        ca := accuracies[keystroke.Expected]
+        addAttempt(&ca, keystroke.Correct, keystroke.Time)
+        accuracies[keystroke.Expected] = ca
+    }
+
+    return accuracies
+}
@@ -0,0 +1,127 @@
+package gamelogic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
+	"github.com/Kaspetti/LayoutLearner/internal/scoring"
+)
+
+// The unlock orders of the characters.
+const (
+    UnlockFrequency = "frequency"   // The most frequent characters of the dictionary first
+    UnlockLayout    = "layout"      // The unlock order of the layout, by default the home row first
+    UnlockCustom    = "custom"      // The characters of the unlock sequence first
+)
+
+
+// defaultSettings returns the settings used for any setting missing from the
+// settings file.
+func defaultSettings() GameSettings {
+    return GameSettings{
+        NumChars: 5,
+        MinWordLength: 3,
+        MaxWordLength: 5,
+        WordCount: 10,
+        TargetCPM: 250,
+        TimeWeight: 0.5,
+        AccuracyWeight: 0.5,
+        GhostMode: GhostBest,
+        Scorer: scoring.Linear,
+        Scheduler: SchedulerScore,
+        PriorityCount: 1,
+        EMAAlpha: 0.1,
+        ScoreWindow: 50,
+        ErrorPolicy: PolicyFree,
+        Layout: "qwerty",
+        DictionaryPath: "resources/words.txt",
+        UnlockOrder: UnlockFrequency,
+        Theme: "default",
+    }
+}
+
+
+// loadSettings loads the settings from settings.json on top of the default
+// settings. If no file exists the default settings are used.
+func loadSettings() (GameSettings, error) {
+    settings := defaultSettings()
+    if _, err := os.Stat(savePath("settings.json")); errors.Is(err, os.ErrNotExist) {
+        return settings, nil
+    }
+
+    saveData, err := os.ReadFile(savePath("settings.json"))
+    if err != nil {
+        return settings, err
+    }
+
+    if err := json.Unmarshal(saveData, &settings); err != nil {
+        return settings, err
+    }
+
+    if settings.Scheduler != SchedulerScore && settings.Scheduler != SchedulerSRS {
+        return settings, fmt.Errorf("unknown scheduler %q, expected %s or %s", settings.Scheduler, SchedulerScore, SchedulerSRS)
+    }
+
+    if settings.UnlockOrder != UnlockFrequency && settings.UnlockOrder != UnlockLayout && settings.UnlockOrder != UnlockCustom {
+        return settings, fmt.Errorf("unknown unlock order %q, expected %s, %s or %s", settings.UnlockOrder, UnlockFrequency, UnlockLayout, UnlockCustom)
+    }
+
+    if settings.ErrorPolicy != PolicyFree && settings.ErrorPolicy != PolicyStop && settings.ErrorPolicy != PolicyStrict {
+        return settings, fmt.Errorf("unknown error policy %q, expected %s, %s or %s", settings.ErrorPolicy, PolicyFree, PolicyStop, PolicyStrict)
+    }
+
+    if settings.NumChars < 1 {
+        return settings, fmt.Errorf("numChars must be positive, got %d", settings.NumChars)
+    }
+
+    if settings.MinWordLength < 1 || settings.MinWordLength > settings.MaxWordLength {
+        return settings, fmt.Errorf("minWordLength must be positive and at most maxWordLength, got %d and %d", settings.MinWordLength, settings.MaxWordLength)
+    }
+
+    if settings.WordCount < 1 {
+        return settings, fmt.Errorf("wordCount must be positive, got %d", settings.WordCount)
+    }
+
+    if settings.TargetCPM < 1 || settings.TargetCPM > scoring.MaxTargetCPM {
+        return settings, fmt.Errorf("targetCPM must be between 1 and %d, got %d", scoring.MaxTargetCPM, settings.TargetCPM)
+    }
+
+    if settings.PriorityCount < 1 {
+        return settings, fmt.Errorf("priorityCount must be positive, got %d", settings.PriorityCount)
+    }
+
+    if err := settings.Goals.validate(); err != nil {
+        return settings, err
+    }
+
+    return settings, nil
+}
+
+
+// unlockOrder orders the characters of the dictionary, most frequent first,
+// in the unlock order selected in the settings.
+func unlockOrder(characterPriority []rune, settings GameSettings, keyboardLayout *layout.Layout) []rune {
+    switch settings.UnlockOrder {
+    case UnlockLayout:
+        return keyboardLayout.UnlockOrder(characterPriority)
+    case UnlockCustom:
+        return layout.OrderFirst(characterPriority, []rune(settings.UnlockSequence))
+    }
+
+    return characterPriority
+}
+
+
+// newScorer returns the scoring strategy selected in the settings.
+func newScorer(settings GameSettings) (scoring.Scorer, error) {
+    return scoring.New(settings.Scorer, scoring.Settings{
+        TargetCPM: settings.TargetCPM,
+        AccuracyWeight: settings.AccuracyWeight,
+        TimeWeight: settings.TimeWeight,
+        Alpha: settings.EMAAlpha,
+        Window: settings.ScoreWindow,
+    })
+}
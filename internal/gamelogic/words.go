@@ -0,0 +1,208 @@
+package gamelogic
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/dictionary"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// problemWordPool is the amount of the worst words the words of a problem
+// words lesson are picked from.
+const problemWordPool = 20
+
+// LessonProblemWords is the lesson type which re-serves the words the
+// player types slowest or misses most.
+const LessonProblemWords = "problemWords"
+
+
+// wordResult is how a single word of a lesson was typed.
+type wordResult struct {
+    Word        string
+    Errors      int         // The amount of characters typed wrong on the first try
+    Corrections int         // The amount of those errors which were corrected
+    Time        int64       // The time spent typing the word in milliseconds
+}
+
+
+// wordResults splits the words of a finished lesson into words and returns
+// how each of them was typed. The time of a word runs from passing the space
+// before it to passing its last character. The tracker is indexed by character, and words
+// past the end of the tracker are left out.
+func wordResults(words string, tracker *correctionTracker) []wordResult {
+    var results []wordResult
+
+    chars := []rune(words)
+    start := 0
+    for i := 0; i <= len(chars); i++ {
+        if i < len(chars) && chars[i] != ' ' {
+            continue
+        }
+        if i > len(tracker.chars) {
+            break
+        }
+
+        if i > start {
+            result := wordResult{ Word: string(chars[start:i]) }
+            for _, char := range tracker.chars[start:i] {
+                if char.Typed && !char.FirstTry {
+                    result.Errors++
+                    if char.Correct {
+                        result.Corrections++
+                    }
+                }
+            }
+
+            result.Time = tracker.chars[i-1].Passed
+            if start > 0 {
+                result.Time -= tracker.chars[start-1].Passed
+            }
+
+            results = append(results, result)
+        }
+        start = i + 1
+    }
+
+    return results
+}
+
+
+// recordWordStats adds how each word of a finished lesson was typed to the
+// word stats of the history. The history is saved with the lesson record.
+func recordWordStats(words string, tracker *correctionTracker) {
+    if history.Words == nil {
+        history.Words = make(map[string]shared.WordStats)
+    }
+
+    now := time.Now().UnixMilli()
+    for _, result := range wordResults(words, tracker) {
+        stats := history.Words[result.Word]
+        stats.Attempts++
+        stats.Errors += result.Errors
+        stats.Corrections += result.Corrections
+        stats.TotalTime += result.Time
+        stats.LastTyped = now
+        history.Words[result.Word] = stats
+    }
+}
+
+
+// deleteWordStats clears the word stats of the history and saves it. The
+// finished lessons and the streak are kept.
+func deleteWordStats() error {
+    history.Words = nil
+    return saveHistory()
+}
+
+
+// problemScore returns how much trouble the player has with a word. It is the
+// share of its characters typed wrong on the first try plus the average
+// time per character relative to the average over all words, so a word typed
+// at the average speed without errors scores 1.
+func problemScore(word string, stats shared.WordStats, averageCharTime float64) float64 {
+    chars := float64(len(word) * stats.Attempts)
+    score := float64(stats.Errors) / chars
+    if averageCharTime > 0 {
+        score += float64(stats.TotalTime) / chars / averageCharTime
+    }
+
+    return score
+}
+
+
+// problemWords returns the words the player has the most trouble with, worst
+// first, together with the problem score of each. Only words using the
+// current characters are included.
+func problemWords(chars []rune) ([]string, []float64) {
+    allowed := make(map[rune]bool, len(chars))
+    for _, char := range chars {
+        allowed[char] = true
+    }
+
+    var totalTime, totalChars int64
+    var words []string
+    for word, stats := range history.Words {
+        if stats.Attempts == 0 || strings.IndexFunc(word, func(r rune) bool { return !allowed[r] }) != -1 {
+            continue
+        }
+
+        totalTime += stats.TotalTime
+        totalChars += int64(len(word) * stats.Attempts)
+        words = append(words, word)
+    }
+
+    averageCharTime := 0.0
+    if totalChars > 0 {
+        averageCharTime = float64(totalTime) / float64(totalChars)
+    }
+
+    scores := make(map[string]float64, len(words))
+    for _, word := range words {
+        scores[word] = problemScore(word, history.Words[word], averageCharTime)
+    }
+
+    // Ties are broken by the word so the same history gives the same lesson
+    sort.Slice(words, func(i, j int) bool {
+        if scores[words[i]] != scores[words[j]] {
+            return scores[words[i]] > scores[words[j]]
+        }
+        return words[i] < words[j]
+    })
+    if len(words) > problemWordPool {
+        words = words[:problemWordPool]
+    }
+
+    weights := make([]float64, len(words))
+    for i, word := range words {
+        weights[i] = scores[word]
+    }
+
+    return words, weights
+}
+
+
+// generateProblemWords generates the words of a lesson from the words the
+// player has the most trouble with using the given seed. Returns an error if
+// no words using the current characters have been typed yet.
+func generateProblemWords(seed int64, amount int) (string, error) {
+    words, weights := problemWords(gameCtx.CurrentChars)
+    if len(words) == 0 {
+        return "", errors.New("no words have been typed with the current characters yet")
+    }
+
+    wordsList := dictionary.PickWords(rand.New(rand.NewSource(seed)), words, weights, amount)
+
+    return joinWords(wordsList), nil
+}
+
+
+// newProblemWordsGame starts a lesson re-serving the words the player has
+// the most trouble with.
+func newProblemWordsGame() {
+    if gameCtx.Settings.NumChars > len(gameCtx.CharacterPriorities) {
+        err := fmt.Errorf("numChars is %d but the dictionary only has %d characters", gameCtx.Settings.NumChars, len(gameCtx.CharacterPriorities))
+        graphicsCtx.ShowErrorScreen("generating problem words", err)
+        inputCaptureChangeChan <- endScreenInputHandler
+        return
+    }
+
+    gameCtx.CurrentChars = gameCtx.CharacterPriorities[:gameCtx.Settings.NumChars]
+    gameCtx.PriorityCharacters = getPriorityCharacters(gameCtx.Settings.PriorityCount)
+    gameCtx.Seed = nextSeed()
+    gameCtx.CourseStage = -1
+    setSource(LessonProblemWords, 0, 0)
+
+    words, err := generateProblemWords(gameCtx.Seed, gameCtx.Settings.WordCount)
+    if err != nil {
+        graphicsCtx.ShowErrorScreen("generating problem words", err)
+        inputCaptureChangeChan <- endScreenInputHandler
+        return
+    }
+
+    startLesson(words)
+}
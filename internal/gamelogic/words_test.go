@@ -0,0 +1,87 @@
+package gamelogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+// typedTracker returns a tracker for the words where every character was
+// typed and passed one second after the previous one. The characters at the
+// indices in wrong were typed wrong on the first try, and the ones in fixed
+// were corrected afterwards.
+func typedTracker(words string, wrong, fixed []int) correctionTracker {
+    tracker := newCorrectionTracker(PolicyFree, len([]rune(words)))
+    for i := range tracker.chars {
+        tracker.chars[i] = charProgress{ Typed: true, FirstTry: true, Correct: true, Passed: int64(i + 1) * 1000 }
+    }
+    for _, i := range wrong {
+        tracker.chars[i].FirstTry = false
+        tracker.chars[i].Correct = false
+    }
+    for _, i := range fixed {
+        tracker.chars[i].Correct = true
+    }
+
+    return tracker
+}
+
+
+func TestWordResults(t *testing.T) {
+    tests := []struct {
+        name        string
+        words       string
+        tracker     correctionTracker
+        want        []wordResult
+    }{
+        {
+            name: "words without errors",
+            words: "the sea ",
+            tracker: typedTracker("the sea ", nil, nil),
+            want: []wordResult{
+                { Word: "the", Time: 3000 },
+                { Word: "sea", Time: 3000 },
+            },
+        },
+        {
+            name: "errors and corrections",
+            words: "the sea ",
+            tracker: typedTracker("the sea ", []int{ 1, 4, 6 }, []int{ 4 }),
+            want: []wordResult{
+                { Word: "the", Errors: 1, Time: 3000 },
+                { Word: "sea", Errors: 2, Corrections: 1, Time: 3000 },
+            },
+        },
+        {
+            name: "characters outside of ascii",
+            words: "café été ",
+            tracker: typedTracker("café été ", []int{ 3 }, nil),
+            want: []wordResult{
+                { Word: "café", Errors: 1, Time: 4000 },
+                { Word: "été", Time: 3000 },
+            },
+        },
+        {
+            name: "tracker shorter than the words",
+            words: "the sea rise ",
+            tracker: typedTracker("the s", nil, nil),
+            want: []wordResult{
+                { Word: "the", Time: 3000 },
+            },
+        },
+        {
+            name: "no words",
+            words: "",
+            tracker: typedTracker("", nil, nil),
+            want: nil,
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            got := wordResults(test.words, &test.tracker)
+            if !reflect.DeepEqual(got, test.want) {
+                t.Errorf("wordResults(%q) = %+v, want %+v", test.words, got, test.want)
+            }
+        })
+    }
+}
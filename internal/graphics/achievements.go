@@ -0,0 +1,51 @@
+package graphics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Achievement is a milestone the player earns once.
+type Achievement struct {
+    Name        string
+    Description string
+    Earned      int64       // When the achievement was earned in milliseconds since unix, 0 if it has not been
+}
+
+
+// ShowAchievements prints every achievement with the day it was earned, and
+// the letters unlocked so far out of all the letters of the dictionary.
+func (gc *GraphicsContext) ShowAchievements(achievements []Achievement, letters []rune, totalLetters int) {
+    gc.clearMain()
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Achievements\n\n", gc.Theme.Heading)
+    for _, achievement := range achievements {
+        if achievement.Earned == 0 {
+            fmt.Fprintf(gc.MainTextView, "[%s]  %-18s %s\n", gc.Theme.Muted, tview.Escape(achievement.Name), tview.Escape(achievement.Description))
+            continue
+        }
+
+        fmt.Fprintf(
+            gc.MainTextView,
+            "[%s]✓ %-18s [%s]%s [%s](%s)\n",
+            gc.Theme.Correct,
+            tview.Escape(achievement.Name),
+            gc.Theme.Text,
+            tview.Escape(achievement.Description),
+            gc.Theme.Muted,
+            time.UnixMilli(achievement.Earned).Format("2006-01-02"),
+        )
+    }
+
+    names := make([]string, len(letters))
+    for i, letter := range letters {
+        names[i] = tview.Escape(charName(letter))
+    }
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Letters unlocked: [%s]%d of %d\n", gc.Theme.Heading, gc.Theme.Text, len(letters), totalLetters)
+    fmt.Fprintf(gc.MainTextView, "[%s]%s\n", gc.Theme.Text, strings.Join(names, " "))
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to go back", gc.Theme.Heading)
+}
@@ -0,0 +1,51 @@
+package graphics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// Confusion is a character typed in place of another.
+type Confusion struct {
+    Expected    rune        // The character which should have been typed
+    Typed       rune        // The character which was typed instead
+    Count       int         // How often the typed character was typed in place of the expected one
+    Share       float64     // The share of all errors on the expected character
+    Layouts     []string    // The layouts with the typed character on the key of the expected one
+}
+
+
+// ShowConfusions prints the most common substitutions of characters, noting
+// the layouts which have the typed character on the key of the expected one
+// in the layout in use.
+func (gc *GraphicsContext) ShowConfusions(layoutName string, confusions []Confusion) {
+    gc.clearMain()
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Most common substitutions (%s)\n\n", gc.Theme.Heading, tview.Escape(layoutName))
+    if len(confusions) == 0 {
+        fmt.Fprintf(gc.MainTextView, "[%s]No characters have been mistyped yet\n", gc.Theme.Muted)
+    } else {
+        fmt.Fprintf(gc.MainTextView, "[%s]%-8s %-6s %6s %8s\n", gc.Theme.Text, "Expected", "Typed", "Count", "Share")
+    }
+
+    for _, confusion := range confusions {
+        fmt.Fprintf(
+            gc.MainTextView,
+            "[%s]%-8s %-6s %6d %7.2f%%",
+            gc.Theme.Text,
+            tview.Escape(charName(confusion.Expected)),
+            tview.Escape(charName(confusion.Typed)),
+            confusion.Count,
+            confusion.Share * 100,
+        )
+
+        if len(confusion.Layouts) > 0 {
+            fmt.Fprintf(gc.MainTextView, " [%s]same key in %s", gc.Theme.Warning, strings.Join(confusion.Layouts, ", "))
+        }
+        fmt.Fprintln(gc.MainTextView)
+    }
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to go back", gc.Theme.Heading)
+}
@@ -0,0 +1,68 @@
+package graphics
+
+import (
+	"fmt"
+)
+
+// DailyProgress is the progress of the player towards their daily goals. A
+// goal of 0 is not tracked.
+type DailyProgress struct {
+    Minutes     float64     // The minutes spent typing today
+    Lessons     int         // The amount of lessons finished today
+    BestWPM     float64     // The highest words per minute of a lesson today
+    MinutesGoal float64
+    LessonsGoal int
+    WPMGoal     float64
+    Streak      int         // The amount of days in a row the goals have been met
+    BestStreak  int         // The longest streak so far
+    GoalsMet    bool        // If the goals have been met today
+}
+
+
+// ShowStartScreen prints the progress towards the daily goals of today and
+// the streak, reminding the player to practise if the goals are not met yet.
+func (gc *GraphicsContext) ShowStartScreen(progress DailyProgress) {
+    gc.clearMain()
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Today's progress\n\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Lessons   %s\n", gc.Theme.Text, gc.goal(float64(progress.Lessons), float64(progress.LessonsGoal), "%.0f"))
+    fmt.Fprintf(gc.MainTextView, "[%s]Minutes   %s\n", gc.Theme.Text, gc.goal(progress.Minutes, progress.MinutesGoal, "%.1f"))
+    fmt.Fprintf(gc.MainTextView, "[%s]Best WPM  %s\n", gc.Theme.Text, gc.goal(progress.BestWPM, progress.WPMGoal, "%.1f"))
+    fmt.Fprintf(gc.MainTextView, "[%s]Streak    %s (best %d)\n\n", gc.Theme.Text, days(progress.Streak), progress.BestStreak)
+
+    if progress.GoalsMet {
+        fmt.Fprintf(gc.MainTextView, "[%s]You have met your goals for today\n", gc.Theme.Correct)
+    } else if progress.Streak > 0 {
+        fmt.Fprintf(gc.MainTextView, "[%s]Meet your goals today to keep your %d day streak\n", gc.Theme.Warning, progress.Streak)
+    } else {
+        fmt.Fprintf(gc.MainTextView, "[%s]Meet your goals today to start a streak\n", gc.Theme.Warning)
+    }
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to start or escape to quit", gc.Theme.Heading)
+}
+
+
+// days formats an amount of days.
+func days(amount int) string {
+    if amount == 1 {
+        return "1 day"
+    }
+
+    return fmt.Sprintf("%d days", amount)
+}
+
+
+// goal formats the progress towards a goal, colored as correct once it is
+// reached. Only the progress is shown if there is no goal.
+func (gc *GraphicsContext) goal(value, goal float64, format string) string {
+    if goal == 0 {
+        return fmt.Sprintf(format, value)
+    }
+
+    color := gc.Theme.Text
+    if value >= goal {
+        color = gc.Theme.Correct
+    }
+
+    return fmt.Sprintf("[%s]" + format + " / " + format + "[%s]", color, value, goal, gc.Theme.Text)
+}
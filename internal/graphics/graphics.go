@@ -4,9 +4,12 @@ package graphics
 
 import (
 	"fmt"
-	"image/color"
+	"sort"
+	"strings"
 
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
 	"github.com/Kaspetti/LayoutLearner/internal/shared"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -16,144 +19,323 @@ type GraphicsContext struct {
     MainTextView        *tview.TextView             // The main text view where the game takes place
     InfoTextView        *tview.TextView             // An information text view to the right of the main text view
     MainFlex            *tview.Flex                 // The main tview flex box containing all other elements
-    MainColorMap        []string                    // The color map for the characters. The colors of each character is a word representing its the color at that index.
+    MainColorMap        []string                    // The state of each character, one of the Char constants. The theme decides the color of each state.
+    GhostIndex          int                         // The index of the character the ghost cursor is on, -1 if there is no ghost
+    GhostLead           int64                       // How far ahead of the ghost the player is in milliseconds, negative if behind
+    PaceIndex           int                         // The index of the character the pace caret is on, -1 if there is no pace caret
+    PaceLead            int                         // How many characters ahead of the pace caret the player is, negative if behind
+    RacePlayers         []shared.RaceProgress       // The progress of every participant when racing, nil when playing alone
+    Theme               *Theme                      // The colors of the TUI
+
+    narrow              bool                        // If the info panel is below the main text view
+    infoHeight          int                         // The height of the info panel when it is below the main text view
+    textWidth           int                         // The width the words are wrapped at
+    textHeight          int                         // The height of the text inside the main text view
+    words               []rune                      // The characters of the words of the lesson last drawn
+    lineStarts          []int                       // The index of the first character of each line of the wrapped words
+    showingWords        bool                        // If the main text view shows the words rather than another screen
+    screen              tcell.Screen                // The screen the TUI was last drawn to, nil before the first frame
 }
 
 
-func InitializeGraphics() GraphicsContext {
+// InitializeGraphics creates the views of the TUI drawn in the given theme.
+func InitializeGraphics(theme *Theme) GraphicsContext {
     graphicsCtx := GraphicsContext{
         App: tview.NewApplication(),
         MainTextView: tview.NewTextView().SetRegions(true).SetDynamicColors(true),
         InfoTextView: tview.NewTextView().SetRegions(true).SetDynamicColors(true),
         MainFlex: tview.NewFlex(),
+        GhostIndex: -1,
+        PaceIndex: -1,
     }
-    graphicsCtx.MainFlex.
-        AddItem(graphicsCtx.MainTextView, 0, 1, true).
-        AddItem(graphicsCtx.InfoTextView, 31, 1, false)
+    graphicsCtx.arrange(false)
 
     graphicsCtx.MainTextView.SetBorder(true)
     graphicsCtx.InfoTextView.SetBorder(true)
 
     graphicsCtx.MainTextView.Highlight("0")
+    graphicsCtx.SetTheme(theme)
 
     return graphicsCtx
 }
 
 
-// DrawText draws the words to the textView giving each character the colors
-// by index listed in the given color map.
-func (gc *GraphicsContext) DrawText(words string, priorityChar rune, currentChars []rune, characterAccuracies map[rune]shared.CharacterAccuracy) {
-    gc.MainTextView.Clear()
+// SetTheme changes the colors of the TUI. The text of the views is drawn in
+// the new colors the next time it is drawn.
+func (gc *GraphicsContext) SetTheme(theme *Theme) {
+    gc.Theme = theme
+
+    background := tcell.GetColor(theme.Background)
+    border := tcell.GetColor(theme.Border)
+    for _, view := range []*tview.TextView{ gc.MainTextView, gc.InfoTextView } {
+        view.SetBackgroundColor(background)
+        view.SetBorderColor(border)
+        view.SetTextColor(tcell.GetColor(theme.Text))
+    }
+    gc.MainFlex.SetBackgroundColor(background)
+}
+
+
+// DrawText draws the words to the main text view giving each character the
+// color of its state in the color map, and the live stats of the lesson to
+// the info panel.
+func (gc *GraphicsContext) DrawText(words string, priorityChars []rune, stats SessionStats) {
     gc.InfoTextView.Clear()
 
     // Draw the words to the main text view
-    for i, char := range words {
-        if char == ' ' && i < len(words) - 1{
-            fmt.Fprintf(gc.MainTextView, `["%d"][%s][::u] [::-]`, i, gc.MainColorMap[i])
-            continue
-        }
-        fmt.Fprintf(gc.MainTextView, `["%d"][%s]%c[""]`, i, gc.MainColorMap[i], char)
-    }        
+    gc.words = []rune(words)
+    gc.showingWords = true
+    gc.writeWords()
 
     // Draw information
-    fmt.Fprint(gc.InfoTextView, "[yellow]Accuracy:\n")
-    for i, char := range currentChars {
-        color := "white"
-        if characterAccuracies[char].Score != -1 {
-            color = interpolateColor(characterAccuracies[char].Score)
-        } 
-        fmt.Fprintf(
-            gc.InfoTextView,
-            `["usedChars"][%s]%c[""]`,
-            color,
-            char,
-        )
+    gc.drawSessionStats(stats)
 
-        if i < len(currentChars) - 1 {
-            fmt.Fprint(gc.InfoTextView, "[white][u]|")
+    fmt.Fprintf(gc.InfoTextView, "\n\n[%s]Priority: ", gc.Theme.Heading)
+    for i, char := range priorityChars {
+        priorityColor := gc.Theme.Text
+        if score := stats.lifetimeScore(char); score != -1 {
+            priorityColor = gc.Theme.scoreColor(score)
         }
-    }
+        fmt.Fprintf(gc.InfoTextView, "[%s][\"usedChars\"]%c[\"\"]", priorityColor, char)
 
-    priortiyColor := "white"
-    if characterAccuracies[priorityChar].Score != -1 {
-        priortiyColor = interpolateColor(characterAccuracies[priorityChar].Score)
+        if i < len(priorityChars) - 1 {
+            fmt.Fprintf(gc.InfoTextView, "[%s] ", gc.Theme.Text)
+        }
+    }
+    fmt.Fprintf(gc.InfoTextView, "[%s]", gc.Theme.Text)
+
+    if gc.GhostIndex >= 0 {
+        fmt.Fprintf(gc.InfoTextView, "\n\n[%s]Ghost: ", gc.Theme.Heading)
+        if gc.GhostLead >= 0 {
+            fmt.Fprintf(gc.InfoTextView, "[%s]%.2fs ahead[%s]", gc.Theme.Correct, float64(gc.GhostLead) / 1000.0, gc.Theme.Text)
+        } else {
+            fmt.Fprintf(gc.InfoTextView, "[%s]%.2fs behind[%s]", gc.Theme.Warning, float64(-gc.GhostLead) / 1000.0, gc.Theme.Text)
+        }
     }
-    fmt.Fprintf(gc.InfoTextView, "\n\n[yellow]Priority: [%s][\"usedChars\"]%c[\"\"][white]", priortiyColor, priorityChar)
 
-    fmt.Fprintf(gc.InfoTextView, "\n\n[yellow]Average times:")
-    for char, ca := range characterAccuracies {
-        if ca.AverageTime >= 1000 {
-            averageTime := float64(ca.AverageTime) / 1000.0
-            fmt.Fprintf(gc.InfoTextView, "\n%c=%.2fs", char, averageTime)
-            continue
+    if gc.PaceIndex >= 0 {
+        fmt.Fprintf(gc.InfoTextView, "\n\n[%s]Pace: ", gc.Theme.Heading)
+        if gc.PaceLead >= 0 {
+            fmt.Fprintf(gc.InfoTextView, "[%s]%d chars ahead[%s]", gc.Theme.Correct, gc.PaceLead, gc.Theme.Text)
+        } else {
+            fmt.Fprintf(gc.InfoTextView, "[%s]%d chars behind[%s]", gc.Theme.Warning, -gc.PaceLead, gc.Theme.Text)
         }
-        fmt.Fprintf(gc.InfoTextView, "\n%c=%dms", char, ca.AverageTime)
     }
 
-    fmt.Fprintf(gc.InfoTextView, "\n\n[yellow]Scores:")
-    for char, ca := range characterAccuracies {
-        fmt.Fprintf(gc.InfoTextView, "\n%c=%.2f", char, ca.Score)
+    if gc.RacePlayers != nil {
+        fmt.Fprintf(gc.InfoTextView, "\n\n[%s]Race:", gc.Theme.Heading)
+        for _, player := range gc.RacePlayers {
+            fmt.Fprintf(gc.InfoTextView, "\n[%s]%s", gc.Theme.Text, gc.progressBar(player))
+        }
     }
 
+    fmt.Fprint(gc.InfoTextView, "\n\n")
+    gc.drawCharTable(stats)
+
     gc.InfoTextView.Highlight("usedChars")
 } 
 
 
-// showEndScreen prints the end screen for the game, providing the user 
-// with information about their accuracy.
-func (gc *GraphicsContext) ShowEndScreen(correct, incorrect float64) {
-    gc.MainTextView.Clear()
-    accuracy := (correct * 100) / (correct + incorrect)
-
-    fmt.Fprintf(gc.MainTextView, "[white]Your accuracy was: %.2f\n", accuracy)
-    fmt.Fprintf(gc.MainTextView, "[yellow]Press enter to continue\n")
-    fmt.Fprintf(gc.MainTextView, "[red]Press escape to exit...\n\n")
-    fmt.Fprintf(gc.MainTextView, "[yellow]Press 1 to clear save file")
+// Beep rings the terminal bell. Does nothing before the first frame is drawn.
+func (gc *GraphicsContext) Beep() {
+    if gc.screen != nil {
+        gc.screen.Beep()
+    }
 }
 
 
 func (gc *GraphicsContext) ShowErrorScreen(while string, err error) {
-    gc.MainTextView.Clear()
+    gc.clearMain()
 
-    fmt.Fprintf(gc.MainTextView, "[red]An error occured while %s.\n\n%s", while, err)
+    fmt.Fprintf(gc.MainTextView, "[%s]An error occured while %s.\n\n%s", gc.Theme.Warning, while, err)
 }
 
 
 func (gc *GraphicsContext) ShowConfirmDeleteSaveScreen() {
-    gc.MainTextView.Clear()
+    gc.clearMain()
 
-    fmt.Fprintf(gc.MainTextView, "[white]Are you sure you want to clear the save file?\n")
-    fmt.Fprintf(gc.MainTextView, "[yellow][1] No\n")
-    fmt.Fprintf(gc.MainTextView, "[red][2] Yes\n")
+    fmt.Fprintf(gc.MainTextView, "[%s]Are you sure you want to clear the save file?\n", gc.Theme.Text)
+    fmt.Fprintf(gc.MainTextView, "[%s]This clears the character scores, mistyped keys, review schedule, word stats\n", gc.Theme.Text)
+    fmt.Fprintf(gc.MainTextView, "[%s]and course progress. The lesson history, streak and achievements are kept.\n\n", gc.Theme.Text)
+    fmt.Fprintf(gc.MainTextView, "[%s][1] No\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s][2] Yes\n", gc.Theme.Warning)
 }
 
 
-func interpolateColor(t float64) string {
-	if t < 0 {
-		t = 0
-	} else if t > 1 {
-		t = 1
-	}
-
-	// Define the RGB values for each color stop
-	color0 := color.RGBA{231, 72, 86, 255}
-	color1 := color.RGBA{249, 241, 165, 255}
-	color2 := color.RGBA{59, 120, 255, 255}
-
-	// Interpolate between the colors based on 't'
-	var r, g, b uint8
-	r = uint8(float64(color0.R)*(1-t) + float64(color1.R)*t)
-	g = uint8(float64(color0.G)*(1-t) + float64(color1.G)*t)
-	b = uint8(float64(color0.B)*(1-t) + float64(color1.B)*t)
-
-	if t >= 0.5 {
-		t = (t - 0.5) * 2 // Scale t for interpolation between color1 and color2
-		r = uint8(float64(color1.R)*(1-t) + float64(color2.R)*t)
-		g = uint8(float64(color1.G)*(1-t) + float64(color2.G)*t)
-		b = uint8(float64(color1.B)*(1-t) + float64(color2.B)*t)
-	}
-
-	// Convert the interpolated color to hex format
-	colorHex := fmt.Sprintf("#%02X%02X%02X", r, g, b)
-	return colorHex
+// ShowRaceLobby prints the players who have joined the race. The host is
+// told how to start the race while the other players are told to wait.
+func (gc *GraphicsContext) ShowRaceLobby(players []shared.RaceProgress, addr string, hosting bool) {
+    gc.clearMain()
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Race lobby on %s\n\n", gc.Theme.Heading, addr)
+    fmt.Fprintf(gc.MainTextView, "[%s]Players:\n", gc.Theme.Text)
+    for _, player := range players {
+        fmt.Fprintf(gc.MainTextView, "[%s]  %s\n", gc.Theme.Text, tview.Escape(player.Name))
+    }
+
+    if hosting {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to start the race\n", gc.Theme.Heading)
+    } else {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Waiting for the host to start the race...\n", gc.Theme.Heading)
+    }
+    fmt.Fprintf(gc.MainTextView, "[%s]Press escape to exit...", gc.Theme.Warning)
+}
+
+
+// ShowRaceResults prints the results table of the race. Players who have
+// finished are ranked by their time, followed by the players still typing.
+func (gc *GraphicsContext) ShowRaceResults(players []shared.RaceProgress, hosting bool) {
+    gc.clearMain()
+
+    ranked := make([]shared.RaceProgress, len(players))
+    copy(ranked, players)
+    sort.SliceStable(ranked, func(i, j int) bool {
+        if ranked[i].Finished != ranked[j].Finished {
+            return ranked[i].Finished
+        }
+        if ranked[i].Finished {
+            return ranked[i].Time < ranked[j].Time
+        }
+        return ranked[i].Index > ranked[j].Index
+    })
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Race results\n\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]%-3s %-16s %8s %6s %8s\n", gc.Theme.Text, "#", "Name", "Time", "CPM", "Accuracy")
+    for i, player := range ranked {
+        accuracy := 0.0
+        if player.Correct + player.Incorrect > 0 {
+            accuracy = float64(player.Correct * 100) / float64(player.Correct + player.Incorrect)
+        }
+
+        if !player.Finished {
+            fmt.Fprintf(gc.MainTextView, "[%s]%-3s %-16.16s %8s %6s %7.2f%%\n", gc.Theme.Muted, "-", tview.Escape(player.Name), "typing", "-", accuracy)
+            continue
+        }
+
+        cpm := 0
+        if player.Time > 0 {
+            cpm = int(float64(player.Total) * 60000 / float64(player.Time))
+        }
+        fmt.Fprintf(
+            gc.MainTextView,
+            "[%s]%-3d %-16.16s %7.2fs %6d %7.2f%%\n",
+            gc.Theme.Text,
+            i + 1,
+            tview.Escape(player.Name),
+            float64(player.Time) / 1000.0,
+            cpm,
+            accuracy,
+        )
+    }
+
+    if hosting {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to start a new race\n", gc.Theme.Heading)
+    } else {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Waiting for the host to start a new race...\n", gc.Theme.Heading)
+    }
+    fmt.Fprintf(gc.MainTextView, "[%s]Press escape to exit...", gc.Theme.Warning)
+}
+
+
+// ShowFingerStats prints the accuracy of each finger and hand of the layout
+// together with the errors and corrections of each in the recent lessons, and
+// how often the keys typed in them used the same finger twice in a row and
+// how often they alternated hands.
+func (gc *GraphicsContext) ShowFingerStats(layoutName string, fingers, hands []layout.GroupAccuracy, recentFingers, recentHands []layout.GroupKeystrokes, transitions layout.Transitions) {
+    gc.clearMain()
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Finger stats (%s)\n\n", gc.Theme.Heading, tview.Escape(layoutName))
+    fmt.Fprintf(gc.MainTextView, "[%s]%-13s %8s %9s %6s %6s\n", gc.Theme.Text, "", "Attempts", "Accuracy", "Time", "Score")
+    for _, group := range fingers {
+        fmt.Fprintln(gc.MainTextView, gc.groupLine(group))
+    }
+
+    fmt.Fprintln(gc.MainTextView)
+    for _, group := range hands {
+        fmt.Fprintln(gc.MainTextView, gc.groupLine(group))
+    }
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Recent lessons\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]%-13s %10s %6s %6s %11s\n", gc.Theme.Text, "", "Keystrokes", "Errors", "Rate", "Corrections")
+    for _, group := range recentFingers {
+        fmt.Fprintln(gc.MainTextView, gc.keystrokesLine(group))
+    }
+
+    fmt.Fprintln(gc.MainTextView)
+    for _, group := range recentHands {
+        fmt.Fprintln(gc.MainTextView, gc.keystrokesLine(group))
+    }
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Bigrams typed:       %6d\n", gc.Theme.Text, transitions.Bigrams)
+    fmt.Fprintf(gc.MainTextView, "[%s]Same finger bigrams: %6.2f%%\n", gc.Theme.Text, transitions.SameFingerRate() * 100)
+    fmt.Fprintf(gc.MainTextView, "[%s]Hand alternation:    %6.2f%%\n", gc.Theme.Text, transitions.AlternationRate() * 100)
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to go back", gc.Theme.Heading)
+}
+
+
+// groupLine returns a line of the finger stats table coloured by the score
+// of the finger or hand.
+func (gc *GraphicsContext) groupLine(group layout.GroupAccuracy) string {
+    if group.Attempts == 0 {
+        return fmt.Sprintf("[%s]%-13s %8d %9s %6s %6s", gc.Theme.Muted, group.Name, 0, "-", "-", "-")
+    }
+
+    return fmt.Sprintf(
+        "[%s]%-13s %8d %8.2f%% %4dms %6.2f",
+        gc.Theme.scoreColor(group.Score),
+        group.Name,
+        group.Attempts,
+        group.Accuracy() * 100,
+        group.AverageTime(),
+        group.Score,
+    )
+}
+
+
+// keystrokesLine returns a line of the recent keystrokes table of the finger
+// stats.
+func (gc *GraphicsContext) keystrokesLine(group layout.GroupKeystrokes) string {
+    if group.Keystrokes == 0 {
+        return fmt.Sprintf("[%s]%-13s %10d %6s %6s %11s", gc.Theme.Muted, group.Name, 0, "-", "-", "-")
+    }
+
+    return fmt.Sprintf(
+        "[%s]%-13s %10d %6d %5.1f%% %11d",
+        gc.Theme.Text,
+        group.Name,
+        group.Keystrokes,
+        group.Errors,
+        group.ErrorRate() * 100,
+        group.Corrections,
+    )
+}
+
+
+// progressBar returns a line showing the name of a race participant and a
+// bar filled according to how far they have come.
+func (gc *GraphicsContext) progressBar(player shared.RaceProgress) string {
+    const barWidth = 12
+
+    filled := 0
+    percent := 0
+    if player.Total > 0 {
+        filled = player.Index * barWidth / player.Total
+        percent = player.Index * 100 / player.Total
+    }
+    if player.Finished {
+        filled = barWidth
+        percent = 100
+    }
+
+    return fmt.Sprintf(
+        "%-10.10s [%s]%s[%s]%s[%s] %3d%%",
+        tview.Escape(player.Name),
+        gc.Theme.Correct,
+        strings.Repeat("█", filled),
+        gc.Theme.Muted,
+        strings.Repeat("░", barWidth - filled),
+        gc.Theme.Text,
+        percent,
+    )
 }
@@ -0,0 +1,194 @@
+package graphics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// LessonReport is the report of a finished lesson shown on the end screen.
+type LessonReport struct {
+    WPM             float64         // The words per minute of the lesson, counting five characters as a word
+    Accuracy        float64         // The share of correct keystrokes
+    FirstTryErrors  int             // The amount of characters typed wrong on the first try
+    Corrected       int             // The amount of those characters which were corrected
+    MeanTime        float64         // The mean time between keystrokes in milliseconds
+    Consistency     float64         // The standard deviation of the time between keystrokes in milliseconds
+    Slowest         []KeyTime       // The keys with the highest average time in the lesson, slowest first
+    Missed          []MissedKey     // The keys typed wrong most often in the lesson, most missed first
+    Deltas          []ScoreDelta    // How the score of each character of the lesson changed
+    AverageLessons  int             // The amount of earlier lessons the averages are taken over, 0 if there are none
+    AverageWPM      float64         // The average words per minute of the earlier lessons
+    AverageAccuracy float64         // The average accuracy of the earlier lessons
+    Achievements    []Achievement   // The achievements earned by the lesson
+    Course          *CourseResult   // The result of the lesson in the course, nil if it was not part of one
+}
+
+
+// CourseResult is the result of a lesson of a course stage.
+type CourseResult struct {
+    Name            string      // The name of the course
+    Stage           int         // The index of the stage of the lesson
+    Stages          int         // The amount of stages in the course
+    StageName       string      // The name of the stage of the lesson
+    Passed          bool        // If the lesson passed the stage
+    TargetWPM       float64     // The words per minute needed to pass the stage
+    TargetAccuracy  float64     // The accuracy needed to pass the stage
+    NextStageName   string      // The name of the stage after a passed one, empty if the course is completed
+}
+
+
+// KeyTime is the average time spent on a key in a lesson.
+type KeyTime struct {
+    Char        rune
+    AverageTime int64       // The average time in milliseconds
+}
+
+
+// MissedKey is a key typed wrong in a lesson together with what was typed
+// instead.
+type MissedKey struct {
+    Char        rune
+    Count       int             // The amount of times the key was typed wrong
+    Typed       []Substitution  // What was typed instead, most frequent first
+}
+
+
+// Substitution is a character typed in place of another.
+type Substitution struct {
+    Char        rune
+    Count       int
+}
+
+
+// ScoreDelta is the change of the score of a character caused by a lesson.
+type ScoreDelta struct {
+    Char        rune
+    Before      float64     // The score before the lesson, -1 if the character was never attempted
+    After       float64     // The score after the lesson
+}
+
+
+// ShowEndScreen prints the report of the finished lesson and the options of
+// the end screen, including the current ghost mode.
+func (gc *GraphicsContext) ShowEndScreen(report LessonReport, ghostMode string) {
+    gc.clearMain()
+
+    fmt.Fprintf(gc.MainTextView, "[%s]Lesson report\n\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]WPM:         %s\n", gc.Theme.Text, gc.compare(report.WPM, report.AverageWPM, report.AverageLessons > 0, "%.1f"))
+    fmt.Fprintf(gc.MainTextView, "[%s]Accuracy:    %s\n", gc.Theme.Text, gc.compare(report.Accuracy * 100, report.AverageAccuracy * 100, report.AverageLessons > 0, "%.2f%%"))
+    fmt.Fprintf(gc.MainTextView, "[%s]Consistency: ±%.0fms around %.0fms between keystrokes\n", gc.Theme.Text, report.Consistency, report.MeanTime)
+    fmt.Fprintf(gc.MainTextView, "[%s]First try errors: %d (%d corrected)\n", gc.Theme.Text, report.FirstTryErrors, report.Corrected)
+    if report.AverageLessons > 0 {
+        fmt.Fprintf(gc.MainTextView, "[%s]Compared to the average of your last %d lessons\n", gc.Theme.Muted, report.AverageLessons)
+    }
+
+    if len(report.Slowest) > 0 {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Slowest keys: ", gc.Theme.Heading)
+        keys := make([]string, len(report.Slowest))
+        for i, key := range report.Slowest {
+            keys[i] = fmt.Sprintf("%s %s", tview.Escape(charName(key.Char)), formatTime(key.AverageTime))
+        }
+        fmt.Fprintf(gc.MainTextView, "[%s]%s", gc.Theme.Text, strings.Join(keys, ", "))
+    }
+
+    if len(report.Missed) > 0 {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Most missed:  ", gc.Theme.Heading)
+        keys := make([]string, len(report.Missed))
+        for i, key := range report.Missed {
+            typed := make([]string, len(key.Typed))
+            for j, substitution := range key.Typed {
+                typed[j] = fmt.Sprintf("%s×%d", tview.Escape(charName(substitution.Char)), substitution.Count)
+            }
+            keys[i] = fmt.Sprintf("%s %d (typed %s)", tview.Escape(charName(key.Char)), key.Count, strings.Join(typed, " "))
+        }
+        fmt.Fprintf(gc.MainTextView, "[%s]%s", gc.Theme.Text, strings.Join(keys, ", "))
+    }
+
+    if len(report.Deltas) > 0 {
+        fmt.Fprintf(gc.MainTextView, "\n\n[%s]Score changes:\n", gc.Theme.Heading)
+        for _, delta := range report.Deltas {
+            fmt.Fprintf(gc.MainTextView, "[%s]%-4s %s\n", gc.Theme.Text, tview.Escape(charName(delta.Char)), gc.scoreDelta(delta))
+        }
+    }
+
+    if report.Course != nil {
+        gc.showCourseResult(*report.Course)
+    }
+
+    if len(report.Achievements) > 0 {
+        fmt.Fprintf(gc.MainTextView, "\n[%s]Achievements earned:\n", gc.Theme.Heading)
+        for _, achievement := range report.Achievements {
+            fmt.Fprintf(gc.MainTextView, "[%s]✓ %s [%s]%s\n", gc.Theme.Correct, tview.Escape(achievement.Name), gc.Theme.Muted, tview.Escape(achievement.Description))
+        }
+    }
+
+    fmt.Fprintf(gc.MainTextView, "\n[%s]Press enter to continue\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press escape to exit...\n\n", gc.Theme.Warning)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 1 to clear save file\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 2 to retry this lesson\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 3 to change ghost (current: %s)\n", gc.Theme.Heading, ghostMode)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 4 to show finger stats\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 5 to practice problem words\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 6 to change theme (current: %s)\n", gc.Theme.Heading, tview.Escape(gc.Theme.Name))
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 7 to show mistyped keys\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.MainTextView, "[%s]Press 8 to show achievements", gc.Theme.Heading)
+}
+
+
+// showCourseResult prints whether the lesson passed its course stage and
+// what comes next.
+func (gc *GraphicsContext) showCourseResult(result CourseResult) {
+    fmt.Fprintf(
+        gc.MainTextView,
+        "\n[%s]Course: [%s]%s, stage %d of %d (%s)\n",
+        gc.Theme.Heading,
+        gc.Theme.Text,
+        tview.Escape(result.Name),
+        result.Stage + 1,
+        result.Stages,
+        tview.Escape(result.StageName),
+    )
+
+    if !result.Passed {
+        fmt.Fprintf(gc.MainTextView, "[%s]Reach %.1f WPM and %.2f%% accuracy to pass the stage\n", gc.Theme.Warning, result.TargetWPM, result.TargetAccuracy * 100)
+    } else if result.NextStageName != "" {
+        fmt.Fprintf(gc.MainTextView, "[%s]Stage passed! [%s]Next up: %s\n", gc.Theme.Correct, gc.Theme.Text, tview.Escape(result.NextStageName))
+    } else {
+        fmt.Fprintf(gc.MainTextView, "[%s]Course completed! [%s]Lessons adapt to your weakest characters again\n", gc.Theme.Correct, gc.Theme.Text)
+    }
+}
+
+
+// compare formats a value of the lesson followed by how it differs from the
+// average, colored by whether the lesson was better or worse.
+func (gc *GraphicsContext) compare(value, average float64, hasAverage bool, format string) string {
+    formatted := fmt.Sprintf(format, value)
+    if !hasAverage {
+        return formatted
+    }
+
+    color := gc.Theme.Correct
+    if value < average {
+        color = gc.Theme.Warning
+    }
+
+    return fmt.Sprintf("%s [%s](%+.1f)[%s]", formatted, color, value - average, gc.Theme.Text)
+}
+
+
+// scoreDelta formats the score of a character before and after a lesson,
+// colored by whether it went up or down.
+func (gc *GraphicsContext) scoreDelta(delta ScoreDelta) string {
+    if delta.Before == -1 {
+        return fmt.Sprintf("new -> %.2f", delta.After)
+    }
+
+    color := gc.Theme.Correct
+    if delta.After < delta.Before {
+        color = gc.Theme.Warning
+    }
+
+    return fmt.Sprintf("%.2f -> %.2f [%s](%+.2f)[%s]", delta.Before, delta.After, color, delta.After - delta.Before, gc.Theme.Text)
+}
@@ -0,0 +1,200 @@
+package graphics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+    infoWidth       = 31    // The width of the info panel when it is next to the text
+    minTextRows     = 3     // The least rows of text kept for the words when the info panel is below the text
+    narrowWidth     = 80    // Terminals narrower than this show the info panel below the text
+)
+
+
+// arrange places the info panel next to the main text view, or below it on
+// narrow terminals.
+func (gc *GraphicsContext) arrange(narrow bool) {
+    gc.narrow = narrow
+    gc.MainFlex.Clear()
+
+    if narrow {
+        gc.MainFlex.SetDirection(tview.FlexRow).
+            AddItem(gc.MainTextView, 0, 1, true).
+            AddItem(gc.InfoTextView, gc.infoHeight, 1, false)
+        return
+    }
+
+    gc.MainFlex.SetDirection(tview.FlexColumn).
+        AddItem(gc.MainTextView, 0, 1, true).
+        AddItem(gc.InfoTextView, infoWidth, 1, false)
+}
+
+
+// HandleResize adapts the layout to the size of the screen before every
+// frame. The info panel moves below the text on narrow terminals, where it is
+// as high as its content, the words of the lesson are wrapped again when the
+// width of the text changes, and the text is scrolled so that the line of the
+// cursor stays centred. Always returns false so that the frame is drawn.
+func (gc *GraphicsContext) HandleResize(screen tcell.Screen) bool {
+    gc.screen = screen
+    width, height := screen.Size()
+
+    narrow := width < narrowWidth
+    if narrow != gc.narrow {
+        gc.arrange(narrow)
+    }
+    if infoHeight := gc.infoPanelHeight(height); narrow && infoHeight != gc.infoHeight {
+        gc.infoHeight = infoHeight
+        gc.MainFlex.ResizeItem(gc.InfoTextView, infoHeight, 1)
+    }
+
+    // The size of the text inside the borders of the main text view
+    textWidth, textHeight := width - infoWidth - 2, height - 2
+    if narrow {
+        textWidth, textHeight = width - 2, height - gc.infoHeight - 2
+    }
+    gc.textHeight = textHeight
+
+    if !gc.showingWords {
+        return false
+    }
+
+    if textWidth != gc.textWidth {
+        gc.textWidth = textWidth
+        gc.writeWords()
+    }
+    gc.centreCursor()
+
+    return false
+}
+
+
+// infoPanelHeight returns the height of the info panel below the text on a
+// screen of the given height. The panel fits its content, but always leaves
+// minTextRows rows for the words of the lesson.
+func (gc *GraphicsContext) infoPanelHeight(screenHeight int) int {
+    lines := strings.Count(strings.TrimRight(gc.InfoTextView.GetText(false), "\n"), "\n") + 1
+
+    // The content and the borders of the panel
+    height := lines + 2
+    if limit := screenHeight - minTextRows - 2; height > limit {
+        height = limit
+    }
+    if height < 0 {
+        height = 0
+    }
+
+    return height
+}
+
+
+// clearMain clears the main text view before showing anything other than
+// the words of the lesson.
+func (gc *GraphicsContext) clearMain() {
+    gc.showingWords = false
+    gc.MainTextView.Clear()
+    gc.MainTextView.ScrollToBeginning()
+}
+
+
+// writeWords writes the words of the lesson to the main text view wrapped at
+// the width of the text, giving each character the color of its state in
+// the color map.
+func (gc *GraphicsContext) writeWords() {
+    gc.MainTextView.Clear()
+    gc.lineStarts = wrapWords(gc.words, gc.textWidth)
+
+    line := 1
+    for i, char := range gc.words {
+        if line < len(gc.lineStarts) && i == gc.lineStarts[line] {
+            gc.MainTextView.Write([]byte("\n"))
+            line++
+        }
+
+        background := "-"
+        if i == gc.PaceIndex {
+            background = gc.Theme.Pace
+        } else if i == gc.GhostIndex {
+            background = gc.Theme.Ghost
+        }
+
+        color := gc.Theme.charColor(gc.MainColorMap[i])
+        if char == ' ' && i < len(gc.words) - 1{
+            fmt.Fprintf(gc.MainTextView, `["%d"][%s:%s][::u] [::-][:-]`, i, color, background)
+            continue
+        }
+        fmt.Fprintf(gc.MainTextView, `["%d"][%s:%s]%c[:-][""]`, i, color, background, char)
+    }
+}
+
+
+// centreCursor scrolls the main text view so that the line of the
+// highlighted character is in the middle of the view.
+func (gc *GraphicsContext) centreCursor() {
+    highlights := gc.MainTextView.GetHighlights()
+    if len(highlights) == 0 {
+        return
+    }
+
+    cursor, err := strconv.Atoi(highlights[0])
+    if err != nil {
+        return
+    }
+
+    line := sort.Search(len(gc.lineStarts), func(i int) bool {
+        return gc.lineStarts[i] > cursor
+    }) - 1
+
+    row := line - gc.textHeight / 2
+    if row < 0 {
+        row = 0
+    }
+    gc.MainTextView.ScrollTo(row, 0)
+}
+
+
+// wrapWords returns the index of the first character of each line when the
+// words are wrapped at the given width. Lines are broken after the space
+// ending a word, and words longer than a line are broken where the line ends.
+// A width below 1 leaves the words on a single line.
+func wrapWords(words []rune, width int) []int {
+    starts := []int{ 0 }
+    if width < 1 {
+        return starts
+    }
+
+    lineLength := 0
+    for start := 0; start < len(words); {
+        end := start
+        for end < len(words) && words[end] != ' ' {
+            end++
+        }
+        if end < len(words) {
+            end++
+        }
+        length := end - start
+
+        if lineLength > 0 && lineLength + length > width {
+            starts = append(starts, start)
+            lineLength = 0
+        }
+
+        // Words which do not fit on a line of their own are broken up
+        for length > width {
+            start += width
+            starts = append(starts, start)
+            length -= width
+        }
+
+        lineLength += length
+        start = end
+    }
+
+    return starts
+}
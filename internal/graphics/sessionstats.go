@@ -0,0 +1,113 @@
+package graphics
+
+import (
+	"fmt"
+)
+
+// SessionStats are the live stats of the lesson in progress shown in the
+// info panel.
+type SessionStats struct {
+    WPM         float64     // The words per minute typed so far, counting five characters as a word
+    Accuracy    float64     // The share of correct keystrokes so far, -1 before the first keystroke
+    Elapsed     int64       // The time since the first keystroke in milliseconds
+    Streak      int         // The amount of correct keystrokes in a row up to now
+    BestStreak  int         // The longest streak of the lesson
+    Remaining   int         // The amount of characters left to type
+    Chars       []CharStats // The stats of the current characters in the order they are listed
+}
+
+
+// CharStats compares the lifetime score of a character with its score in
+// the lesson in progress.
+type CharStats struct {
+    Char        rune        // The character
+    Lifetime    float64     // The score over every attempt, -1 if never attempted
+    Session     float64     // The score over the attempts of the lesson, -1 if not attempted in the lesson
+    AverageTime int64       // The lifetime average time spent per attempt in milliseconds
+}
+
+
+// lifetimeScore returns the lifetime score of the character, or -1 if it is
+// not among the stats.
+func (s SessionStats) lifetimeScore(char rune) float64 {
+    for _, stats := range s.Chars {
+        if stats.Char == char {
+            return stats.Lifetime
+        }
+    }
+
+    return -1
+}
+
+
+// drawSessionStats prints the live stats of the lesson to the info panel.
+func (gc *GraphicsContext) drawSessionStats(stats SessionStats) {
+    accuracy := "-"
+    if stats.Accuracy >= 0 {
+        accuracy = fmt.Sprintf("%.2f%%", stats.Accuracy * 100)
+    }
+
+    fmt.Fprintf(gc.InfoTextView, "[%s]Session\n", gc.Theme.Heading)
+    fmt.Fprintf(gc.InfoTextView, "[%s]WPM        %.1f\n", gc.Theme.Text, stats.WPM)
+    fmt.Fprintf(gc.InfoTextView, "[%s]Accuracy   %s\n", gc.Theme.Text, accuracy)
+    fmt.Fprintf(gc.InfoTextView, "[%s]Elapsed    %d:%02d\n", gc.Theme.Text, stats.Elapsed / 60000, stats.Elapsed / 1000 % 60)
+    fmt.Fprintf(gc.InfoTextView, "[%s]Streak     %d (best %d)\n", gc.Theme.Text, stats.Streak, stats.BestStreak)
+    fmt.Fprintf(gc.InfoTextView, "[%s]Remaining  %d", gc.Theme.Text, stats.Remaining)
+}
+
+
+// drawCharTable prints the lifetime and session score and the average time
+// of each current character to the info panel, each character colored by
+// its lifetime score.
+func (gc *GraphicsContext) drawCharTable(stats SessionStats) {
+    fmt.Fprintf(gc.InfoTextView, "[%s]%-4s %5s  %5s  %6s", gc.Theme.Heading, "Char", "Life", "Now", "Time")
+    for _, char := range stats.Chars {
+        color := gc.Theme.Text
+        if char.Lifetime != -1 {
+            color = gc.Theme.scoreColor(char.Lifetime)
+        }
+
+        fmt.Fprintf(
+            gc.InfoTextView,
+            "\n[%s]%-4s [%s]%5s  %5s  %6s",
+            color,
+            charName(char.Char),
+            gc.Theme.Text,
+            formatScore(char.Lifetime),
+            formatScore(char.Session),
+            formatTime(char.AverageTime),
+        )
+    }
+}
+
+
+// charName returns the character as it is shown in tables, naming the
+// space since it can not be seen.
+func charName(char rune) string {
+    if char == ' ' {
+        return "spc"
+    }
+
+    return string(char)
+}
+
+
+// formatScore formats a score, or a dash if there is none.
+func formatScore(score float64) string {
+    if score == -1 {
+        return "-"
+    }
+
+    return fmt.Sprintf("%.2f", score)
+}
+
+
+// formatTime formats a time in milliseconds, switching to seconds from one
+// second and up.
+func formatTime(ms int64) string {
+    if ms >= 1000 {
+        return fmt.Sprintf("%.2fs", float64(ms) / 1000.0)
+    }
+
+    return fmt.Sprintf("%dms", ms)
+}
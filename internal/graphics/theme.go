@@ -0,0 +1,219 @@
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// The states of the characters of a lesson stored in the color map. Each
+// state is drawn in the color the theme gives it.
+const (
+    CharUntyped     = "untyped"     // The character has not been typed yet
+    CharCorrect     = "correct"     // The character was typed correctly on the first try
+    CharIncorrect   = "incorrect"   // The latest keystroke on the character was wrong
+    CharCorrected   = "corrected"   // The character was typed wrong and corrected afterwards
+)
+
+
+// Theme defines the colors of the TUI. Colors are W3C color names such as
+// "white" or hex values such as "#FFFFFF".
+type Theme struct {
+    Name        string      `json:"name"`           // The name of the theme
+    Background  string      `json:"background"`     // The background of every view
+    Border      string      `json:"border"`         // The borders of the views
+    Text        string      `json:"text"`           // Regular text and untyped characters
+    Heading     string      `json:"heading"`        // Headings and key prompts
+    Warning     string      `json:"warning"`        // Errors, exit prompts and falling behind the ghost
+    Muted       string      `json:"muted"`          // Inactive entries such as players still racing
+    Correct     string      `json:"correct"`        // Characters typed correctly on the first try and being ahead of the ghost
+    Incorrect   string      `json:"incorrect"`      // Characters typed wrong
+    Corrected   string      `json:"corrected"`      // Characters typed wrong and corrected afterwards
+    Ghost       string      `json:"ghost"`          // The background of the character the ghost cursor is on
+    Pace        string      `json:"pace"`           // The background of the character the pace caret is on
+    Gradient    []string    `json:"gradient"`       // The colors of the scores from 0 to 1, evenly spaced
+}
+
+
+// themeOrder is the order the built-in themes are cycled through in.
+var themeOrder = []string{ "default", "high-contrast", "colorblind" }
+
+var builtinThemes = map[string]Theme{
+    "default": {
+        Name: "default",
+        Background: "black",
+        Border: "white",
+        Text: "white",
+        Heading: "yellow",
+        Warning: "red",
+        Muted: "gray",
+        Correct: "blue",
+        Incorrect: "red",
+        Corrected: "yellow",
+        Ghost: "gray",
+        Pace: "teal",
+        Gradient: []string{ "#E74856", "#F9F1A5", "#3B78FF" },
+    },
+    "high-contrast": {
+        Name: "high-contrast",
+        Background: "black",
+        Border: "white",
+        Text: "white",
+        Heading: "#FFFF00",
+        Warning: "#FF0000",
+        Muted: "silver",
+        Correct: "#00FF00",
+        Incorrect: "#FF0000",
+        Corrected: "#FFFF00",
+        Ghost: "#0000FF",
+        Pace: "#FF00FF",
+        Gradient: []string{ "#FF0000", "#FFFF00", "#00FF00" },
+    },
+    // Uses the Okabe-Ito palette, telling correct from incorrect by blue
+    // and orange which stay apart for the common kinds of color blindness
+    "colorblind": {
+        Name: "colorblind",
+        Background: "black",
+        Border: "white",
+        Text: "white",
+        Heading: "#F0E442",
+        Warning: "#D55E00",
+        Muted: "gray",
+        Correct: "#56B4E9",
+        Incorrect: "#E69F00",
+        Corrected: "#CC79A7",
+        Ghost: "#0072B2",
+        Pace: "#009E73",
+        Gradient: []string{ "#D55E00", "#F0E442", "#56B4E9" },
+    },
+}
+
+
+// BuiltinThemeNames returns the names of the built-in themes in the order
+// they are cycled through.
+func BuiltinThemeNames() []string {
+    names := make([]string, len(themeOrder))
+    copy(names, themeOrder)
+
+    return names
+}
+
+
+// LoadTheme returns the built-in theme with the given name, or otherwise
+// loads the theme from the JSON file at the given path. Colors missing from
+// the file are taken from the default theme.
+func LoadTheme(nameOrPath string) (*Theme, error) {
+    var t Theme
+    if builtin, ok := builtinThemes[nameOrPath]; ok {
+        t = builtin
+    } else {
+        data, err := os.ReadFile(nameOrPath)
+        if err != nil {
+            return nil, fmt.Errorf("%q is neither a built-in theme nor a readable theme file: %w", nameOrPath, err)
+        }
+
+        t = builtinThemes["default"]
+        t.Name = nameOrPath
+        if err := json.Unmarshal(data, &t); err != nil {
+            return nil, fmt.Errorf("parsing theme %s: %w", nameOrPath, err)
+        }
+    }
+
+    if err := t.validate(); err != nil {
+        return nil, fmt.Errorf("theme %s: %w", nameOrPath, err)
+    }
+
+    return &t, nil
+}
+
+
+// NextTheme returns the name of the built-in theme following the given one.
+// The first built-in theme follows themes which are not built in.
+func NextTheme(name string) string {
+    for i, builtin := range themeOrder {
+        if builtin == name {
+            return themeOrder[(i + 1) % len(themeOrder)]
+        }
+    }
+
+    return themeOrder[0]
+}
+
+
+// validate checks that every color of the theme is known.
+func (t *Theme) validate() error {
+    colors := map[string]string{
+        "background": t.Background,
+        "border": t.Border,
+        "text": t.Text,
+        "heading": t.Heading,
+        "warning": t.Warning,
+        "muted": t.Muted,
+        "correct": t.Correct,
+        "incorrect": t.Incorrect,
+        "corrected": t.Corrected,
+        "ghost": t.Ghost,
+        "pace": t.Pace,
+    }
+    for field, name := range colors {
+        if tcell.GetColor(name) == tcell.ColorDefault {
+            return fmt.Errorf("unknown %s color %q", field, name)
+        }
+    }
+
+    if len(t.Gradient) < 2 {
+        return fmt.Errorf("the gradient needs at least 2 colors, got %d", len(t.Gradient))
+    }
+    for _, name := range t.Gradient {
+        if r, _, _ := tcell.GetColor(name).RGB(); r < 0 {
+            return fmt.Errorf("unknown gradient color %q", name)
+        }
+    }
+
+    return nil
+}
+
+
+// charColor returns the color of a character in the given state.
+func (t *Theme) charColor(state string) string {
+    switch state {
+    case CharCorrect:
+        return t.Correct
+    case CharIncorrect:
+        return t.Incorrect
+    case CharCorrected:
+        return t.Corrected
+    }
+
+    return t.Text
+}
+
+
+// scoreColor returns the color of a score between 0 and 1 on the gradient
+// of the theme as a hex value.
+func (t *Theme) scoreColor(score float64) string {
+    if score < 0 {
+        score = 0
+    } else if score > 1 {
+        score = 1
+    }
+
+    // Find the two stops the score lies between
+    position := score * float64(len(t.Gradient) - 1)
+    i := int(position)
+    if i >= len(t.Gradient) - 1 {
+        i = len(t.Gradient) - 2
+    }
+    position -= float64(i)
+
+    r0, g0, b0 := tcell.GetColor(t.Gradient[i]).RGB()
+    r1, g1, b1 := tcell.GetColor(t.Gradient[i+1]).RGB()
+
+    r := uint8(float64(r0) * (1 - position) + float64(r1) * position)
+    g := uint8(float64(g0) * (1 - position) + float64(g1) * position)
+    b := uint8(float64(b0) * (1 - position) + float64(b1) * position)
+
+    return fmt.Sprintf("#%02X%02X%02X", r, g, b)
+}
@@ -0,0 +1,159 @@
+package layout
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Analysis describes how a layout is used when typing a set of words. Every
+// count is weighted by how often the word occurs.
+type Analysis struct {
+    Layout      string                  // The name of the analysed layout
+    Keystrokes  int                     // The amount of characters typed which are in the layout
+    Unknown     int                     // The amount of characters typed which are not in the layout
+    HomeRow     int                     // The amount of characters typed on the home row
+    FingerLoad  [FingerCount]int        // The amount of characters typed by each finger
+    RowJumps    int                     // The amount of bigrams typed by one hand jumping over a row
+    Transitions                         // The transitions between consecutive characters
+}
+
+
+// HomeRowRate returns the share of characters typed on the home row.
+func (a Analysis) HomeRowRate() float64 {
+    return rate(a.HomeRow, a.Keystrokes)
+}
+
+
+// FingerRate returns the share of characters typed by the finger.
+func (a Analysis) FingerRate(finger Finger) float64 {
+    return rate(a.FingerLoad[finger], a.Keystrokes)
+}
+
+
+// HandRate returns the share of characters typed by the hand.
+func (a Analysis) HandRate(hand Hand) float64 {
+    load := 0
+    for finger := Finger(0); finger < FingerCount; finger++ {
+        if finger.Hand() == hand {
+            load += a.FingerLoad[finger]
+        }
+    }
+
+    return rate(load, a.Keystrokes)
+}
+
+
+// RowJumpRate returns the share of bigrams typed by one hand jumping over a row.
+func (a Analysis) RowJumpRate() float64 {
+    return rate(a.RowJumps, a.Bigrams)
+}
+
+
+// Analyze analyses typing the words with the layout. Each word is typed as
+// many times as its count.
+func (l *Layout) Analyze(words map[string]int) Analysis {
+    analysis := Analysis{ Layout: l.Name }
+    for word, count := range words {
+        l.analyzeWord(&analysis, word, count)
+    }
+
+    return analysis
+}
+
+
+// CountTransitions counts the transitions between consecutive characters
+// within each word of the text.
+func (l *Layout) CountTransitions(text string) Transitions {
+    var analysis Analysis
+    for _, word := range strings.Fields(text) {
+        l.analyzeWord(&analysis, word, 1)
+    }
+
+    return analysis.Transitions
+}
+
+
+// analyzeWord adds typing the word count times to the analysis. Bigrams
+// with a character which is not in the layout are skipped.
+func (l *Layout) analyzeWord(analysis *Analysis, word string, count int) {
+    var previous Key
+    hasPrevious := false
+    for _, char := range word {
+        key, ok := l.Key(char)
+        if !ok {
+            analysis.Unknown += count
+            hasPrevious = false
+            continue
+        }
+
+        analysis.Keystrokes += count
+        analysis.FingerLoad[key.Finger] += count
+        if key.Row == l.HomeRow {
+            analysis.HomeRow += count
+        }
+
+        if hasPrevious {
+            analysis.Bigrams += count
+            if previous.Finger == key.Finger && previous.Char != key.Char {
+                analysis.SameFinger += count
+            }
+
+            if previous.Finger.Hand() != key.Finger.Hand() {
+                analysis.Alternating += count
+            } else if previous.Row - key.Row >= 2 || key.Row - previous.Row >= 2 {
+                analysis.RowJumps += count
+            }
+        }
+
+        previous = key
+        hasPrevious = true
+    }
+}
+
+
+// WriteComparison writes a table comparing the analyses side by side.
+func WriteComparison(w io.Writer, analyses []Analysis) error {
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+    row := func(label string, value func(Analysis) string) {
+        fmt.Fprintf(tw, "%s\t", label)
+        for _, analysis := range analyses {
+            fmt.Fprintf(tw, "%s\t", value(analysis))
+        }
+        fmt.Fprintln(tw)
+    }
+    percent := func(label string, value func(Analysis) float64) {
+        row(label, func(a Analysis) string {
+            return fmt.Sprintf("%.2f%%", value(a) * 100)
+        })
+    }
+
+    row("", func(a Analysis) string { return a.Layout })
+    percent("Home row", Analysis.HomeRowRate)
+    percent("Same finger bigrams", func(a Analysis) float64 { return a.SameFingerRate() })
+    percent("Hand alternation", func(a Analysis) float64 { return a.AlternationRate() })
+    percent("Row jumps", Analysis.RowJumpRate)
+    percent("Left hand", func(a Analysis) float64 { return a.HandRate(Left) })
+    percent("Right hand", func(a Analysis) float64 { return a.HandRate(Right) })
+    for finger := Finger(0); finger < FingerCount; finger++ {
+        if finger == LeftThumb || finger == RightThumb {
+            continue
+        }
+        percent(finger.String(), func(a Analysis) float64 { return a.FingerRate(finger) })
+    }
+    row("Unknown characters", func(a Analysis) string { return fmt.Sprint(a.Unknown) })
+
+    return tw.Flush()
+}
+
+
+// rate returns count / total, or 0 if total is 0.
+func rate(count, total int) float64 {
+    if total == 0 {
+        return 0
+    }
+
+    return float64(count) / float64(total)
+}
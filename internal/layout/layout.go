@@ -0,0 +1,322 @@
+// Package layout contains keyboard layout definitions. A layout places each character
+// on a row and column of the keyboard and assigns it to the finger which presses it,
+// which makes it possible to analyse typing per finger and per hand.
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"unicode"
+)
+
+// Finger is one of the ten fingers, numbered from the left pinky to the right pinky.
+type Finger int
+
+const (
+    LeftPinky Finger = iota
+    LeftRing
+    LeftMiddle
+    LeftIndex
+    LeftThumb
+    RightThumb
+    RightIndex
+    RightMiddle
+    RightRing
+    RightPinky
+)
+
+// FingerCount is the amount of fingers.
+const FingerCount = 10
+
+// Hand is either the left or the right hand.
+type Hand int
+
+const (
+    Left Hand = iota
+    Right
+)
+
+
+var fingerNames = [FingerCount]string{
+    "left pinky", "left ring", "left middle", "left index", "left thumb",
+    "right thumb", "right index", "right middle", "right ring", "right pinky",
+}
+
+// standardFingers is the finger of each column in standard touch typing,
+// starting at the leftmost letter key. Columns past the end are pressed by
+// the right pinky.
+var standardFingers = []Finger{
+    LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+    RightIndex, RightIndex, RightMiddle, RightRing, RightPinky,
+}
+
+
+func (f Finger) String() string {
+    if f < 0 || f >= FingerCount {
+        return fmt.Sprintf("Finger(%d)", int(f))
+    }
+
+    return fingerNames[f]
+}
+
+
+// Hand returns the hand the finger belongs to.
+func (f Finger) Hand() Hand {
+    if f <= LeftThumb {
+        return Left
+    }
+
+    return Right
+}
+
+
+func (h Hand) String() string {
+    if h == Left {
+        return "left"
+    }
+
+    return "right"
+}
+
+
+// Key is the position of a character on the keyboard.
+type Key struct {
+    Char        rune        // The character typed by the key
+    Row         int         // The row of the key, 0 being the top row of the layout
+    Column      int         // The column of the key, 0 being the leftmost letter key
+    Finger      Finger      // The finger pressing the key
+}
+
+
+// Layout is a keyboard layout definition. The finger of each key defaults
+// to the standard touch typing assignment when Fingers is left out.
+type Layout struct {
+    Name        string      `json:"name"`           // The name of the layout
+    Rows        []string    `json:"rows"`           // The characters of each row from top to bottom, starting at the leftmost letter key
+    Fingers     []string    `json:"fingers"`        // The finger of each key in Rows as digits from 0 (left pinky) to 9 (right pinky)
+    HomeRow     int         `json:"homeRow"`        // The index of the home row in Rows
+    Unlock      string      `json:"unlockOrder"`    // The order the characters are unlocked in, see UnlockOrder
+
+    keys        map[rune]Key
+}
+
+
+var builtins = map[string]Layout{
+    "qwerty": {
+        Name: "qwerty",
+        Rows: []string{ "qwertyuiop[]", "asdfghjkl;'", "zxcvbnm,./" },
+        HomeRow: 1,
+    },
+    "dvorak": {
+        Name: "dvorak",
+        Rows: []string{ "',.pyfgcrl/=", "aoeuidhtns-", ";qjkxbmwvz" },
+        HomeRow: 1,
+    },
+    "colemak": {
+        Name: "colemak",
+        Rows: []string{ "qwfpgjluy;[]", "arstdhneio'", "zxcvbkm,./" },
+        HomeRow: 1,
+    },
+    "colemak-dh": {
+        Name: "colemak-dh",
+        Rows: []string{ "qwfpbjluy;[]", "arstgmneio'", "zxcdvkh,./" },
+        HomeRow: 1,
+    },
+    "workman": {
+        Name: "workman",
+        Rows: []string{ "qdrwbjfup;[]", "ashtgyneoi'", "zxmcvkl,./" },
+        HomeRow: 1,
+    },
+}
+
+
+// BuiltinNames returns the names of the built-in layouts in alphabetical order.
+func BuiltinNames() []string {
+    names := make([]string, 0, len(builtins))
+    for name := range builtins {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    return names
+}
+
+
+// Load returns the built-in layout with the given name, or otherwise loads
+// the layout definition from the JSON file at the given path.
+func Load(nameOrPath string) (*Layout, error) {
+    var l Layout
+    if builtin, ok := builtins[nameOrPath]; ok {
+        l = builtin
+    } else {
+        data, err := os.ReadFile(nameOrPath)
+        if err != nil {
+            return nil, fmt.Errorf("%q is neither a built-in layout nor a readable layout file: %w", nameOrPath, err)
+        }
+
+        if err := json.Unmarshal(data, &l); err != nil {
+            return nil, fmt.Errorf("parsing layout %s: %w", nameOrPath, err)
+        }
+    }
+
+    if err := l.init(); err != nil {
+        return nil, fmt.Errorf("layout %s: %w", nameOrPath, err)
+    }
+
+    return &l, nil
+}
+
+
+// init validates the layout and places its keys. The space bar is pressed
+// by the right thumb.
+func (l *Layout) init() error {
+    if len(l.Rows) == 0 {
+        return fmt.Errorf("the layout has no rows")
+    }
+    if l.HomeRow < 0 || l.HomeRow >= len(l.Rows) {
+        return fmt.Errorf("the home row %d is not one of the %d rows", l.HomeRow, len(l.Rows))
+    }
+    if l.Fingers != nil && len(l.Fingers) != len(l.Rows) {
+        return fmt.Errorf("expected fingers for %d rows, got %d", len(l.Rows), len(l.Fingers))
+    }
+
+    l.keys = make(map[rune]Key)
+    for row, chars := range l.Rows {
+        var fingers []rune
+        if l.Fingers != nil {
+            fingers = []rune(l.Fingers[row])
+        }
+
+        for column, char := range []rune(chars) {
+            finger := RightPinky
+            if column < len(standardFingers) {
+                finger = standardFingers[column]
+            }
+
+            if fingers != nil {
+                if column >= len(fingers) || fingers[column] < '0' || fingers[column] > '9' {
+                    return fmt.Errorf("row %d needs a finger digit for %q", row, char)
+                }
+                finger = Finger(fingers[column] - '0')
+            }
+
+            char = unicode.ToLower(char)
+            if _, ok := l.keys[char]; ok {
+                return fmt.Errorf("%q is placed more than once", char)
+            }
+            l.keys[char] = Key{ Char: char, Row: row, Column: column, Finger: finger }
+        }
+    }
+
+    if _, ok := l.keys[' ']; !ok {
+        l.keys[' '] = Key{ Char: ' ', Row: len(l.Rows), Finger: RightThumb }
+    }
+
+    return nil
+}
+
+
+// Key returns the key typing the character, ignoring case. Returns false if
+// the character is not in the layout.
+func (l *Layout) Key(char rune) (Key, bool) {
+    key, ok := l.keys[unicode.ToLower(char)]
+    return key, ok
+}
+
+
+// CharAt returns the character on the key at the given row and column.
+// Returns false if the layout has no key there.
+func (l *Layout) CharAt(row, column int) (rune, bool) {
+    if row < 0 || row >= len(l.Rows) {
+        return 0, false
+    }
+
+    chars := []rune(l.Rows[row])
+    if column < 0 || column >= len(chars) {
+        return 0, false
+    }
+
+    return unicode.ToLower(chars[column]), true
+}
+
+
+// UnlockOrder orders the characters for unlocking them in lessons on this
+// layout. If the layout has an explicit unlock order its characters come
+// first in that order. Otherwise the home row comes first, followed by the
+// rows closest to it, upper rows before lower ones. Characters keep their
+// given order within a row, and characters which are not placed come last.
+func (l *Layout) UnlockOrder(chars []rune) []rune {
+    if l.Unlock != "" {
+        return OrderFirst(chars, []rune(l.Unlock))
+    }
+
+    ordered := make([]rune, len(chars))
+    copy(ordered, chars)
+
+    rank := func(char rune) int {
+        key, ok := l.Key(char)
+        if !ok || char == ' ' {
+            return 2 * len(l.Rows)
+        }
+
+        distance := key.Row - l.HomeRow
+        if distance < 0 {
+            return -2 * distance - 1
+        }
+        return 2 * distance
+    }
+    sort.SliceStable(ordered, func(i, j int) bool {
+        return rank(ordered[i]) < rank(ordered[j])
+    })
+
+    return ordered
+}
+
+
+// OrderFirst returns the characters with the characters of first moved to
+// the front in the order of first. Characters of first which are not among
+// the characters are left out.
+func OrderFirst(chars, first []rune) []rune {
+    available := make(map[rune]bool, len(chars))
+    for _, char := range chars {
+        available[char] = true
+    }
+
+    ordered := make([]rune, 0, len(chars))
+    for _, char := range first {
+        char = unicode.ToLower(char)
+        if available[char] {
+            ordered = append(ordered, char)
+            available[char] = false
+        }
+    }
+
+    for _, char := range chars {
+        if available[char] {
+            ordered = append(ordered, char)
+        }
+    }
+
+    return ordered
+}
+
+
+// FingerKeys returns the characters pressed by each finger, including the
+// space bar.
+func (l *Layout) FingerKeys() [FingerCount][]rune {
+    var keys [FingerCount][]rune
+    for _, chars := range l.Rows {
+        for _, char := range chars {
+            key, _ := l.Key(char)
+            keys[key.Finger] = append(keys[key.Finger], key.Char)
+        }
+    }
+
+    if space := l.keys[' ']; space.Row == len(l.Rows) {
+        keys[space.Finger] = append(keys[space.Finger], ' ')
+    }
+
+    return keys
+}
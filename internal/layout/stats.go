@@ -0,0 +1,172 @@
+package layout
+
+import (
+	"unicode/utf8"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// GroupAccuracy aggregates the accuracy of the characters pressed by a
+// finger or a hand.
+type GroupAccuracy struct {
+    Name        string      // The name of the finger or hand
+    Keys        []rune      // The characters pressed by the finger or hand
+    Attempts    int64       // The amount of attempts at all of the characters
+    Correct     int64       // The amount of correct attempts at all of the characters
+    TotalTime   int64       // The total time spent on all of the characters in milliseconds
+    Score       float64     // The mean score of the attempted characters, -1 if none have been attempted
+}
+
+
+// Accuracy returns the accuracy of the group (Correct / Attempts).
+func (g GroupAccuracy) Accuracy() float64 {
+    if g.Attempts == 0 {
+        return 0
+    }
+
+    return float64(g.Correct) / float64(g.Attempts)
+}
+
+
+// AverageTime returns the average time spent per attempt in milliseconds.
+func (g GroupAccuracy) AverageTime() int64 {
+    if g.Attempts == 0 {
+        return 0
+    }
+
+    return g.TotalTime / g.Attempts
+}
+
+
+// GroupKeystrokes counts the keystrokes made on the characters pressed by a
+// finger or a hand.
+type GroupKeystrokes struct {
+    Name        string      // The name of the finger or hand
+    Keystrokes  int         // The amount of keystrokes expected on the characters
+    Errors      int         // The amount of those keystrokes which were wrong
+    Corrections int         // The amount of those keystrokes which corrected an earlier error
+}
+
+
+// ErrorRate returns the share of the keystrokes which were wrong.
+func (g GroupKeystrokes) ErrorRate() float64 {
+    return rate(g.Errors, g.Keystrokes)
+}
+
+
+// Transitions counts the transitions between consecutive characters typed
+// within words.
+type Transitions struct {
+    Bigrams     int         // The amount of bigrams with both characters in the layout
+    SameFinger  int         // The amount of bigrams of two different keys pressed by the same finger
+    Alternating int         // The amount of bigrams pressed by different hands
+}
+
+
+// SameFingerRate returns the share of bigrams typed with the same finger.
+func (t Transitions) SameFingerRate() float64 {
+    return rate(t.SameFinger, t.Bigrams)
+}
+
+
+// AlternationRate returns the share of bigrams typed with alternating hands.
+func (t Transitions) AlternationRate() float64 {
+    return rate(t.Alternating, t.Bigrams)
+}
+
+
+// FingerAccuracies aggregates the accuracies of the characters per finger.
+// Fingers without any keys in the layout are left out.
+func (l *Layout) FingerAccuracies(accuracies map[rune]shared.CharacterAccuracy) []GroupAccuracy {
+    groups := make([]GroupAccuracy, 0, FingerCount)
+    for finger, keys := range l.FingerKeys() {
+        if len(keys) == 0 {
+            continue
+        }
+
+        groups = append(groups, aggregate(Finger(finger).String(), keys, accuracies))
+    }
+
+    return groups
+}
+
+
+// HandAccuracies aggregates the accuracies of the characters per hand.
+func (l *Layout) HandAccuracies(accuracies map[rune]shared.CharacterAccuracy) []GroupAccuracy {
+    handKeys := make([][]rune, 2)
+    for finger, keys := range l.FingerKeys() {
+        hand := Finger(finger).Hand()
+        handKeys[hand] = append(handKeys[hand], keys...)
+    }
+
+    return []GroupAccuracy{
+        aggregate(Left.String(), handKeys[Left], accuracies),
+        aggregate(Right.String(), handKeys[Right], accuracies),
+    }
+}
+
+
+// CountKeystrokes counts the keystrokes per finger and per hand by the finger
+// of the character which should have been typed. Keystrokes on characters
+// which are not in the layout are skipped. Fingers without any keys in the
+// layout are left out.
+func (l *Layout) CountKeystrokes(keystrokes []shared.KeystrokeRecord) ([]GroupKeystrokes, []GroupKeystrokes) {
+    var fingers [FingerCount]GroupKeystrokes
+    hands := []GroupKeystrokes{ { Name: Left.String() }, { Name: Right.String() } }
+    for _, keystroke := range keystrokes {
+        expected, _ := utf8.DecodeRuneInString(keystroke.Expected)
+        key, ok := l.Key(expected)
+        if !ok {
+            continue
+        }
+
+        wrong := keystroke.Typed != keystroke.Expected
+        for _, group := range []*GroupKeystrokes{ &fingers[key.Finger], &hands[key.Finger.Hand()] } {
+            group.Keystrokes++
+            if wrong {
+                group.Errors++
+            } else if keystroke.Retry {
+                group.Corrections++
+            }
+        }
+    }
+
+    groups := make([]GroupKeystrokes, 0, FingerCount)
+    for finger, keys := range l.FingerKeys() {
+        if len(keys) == 0 {
+            continue
+        }
+
+        fingers[finger].Name = Finger(finger).String()
+        groups = append(groups, fingers[finger])
+    }
+
+    return groups, hands
+}
+
+
+// aggregate sums the accuracies of the given characters.
+func aggregate(name string, keys []rune, accuracies map[rune]shared.CharacterAccuracy) GroupAccuracy {
+    group := GroupAccuracy{ Name: name, Keys: keys, Score: -1 }
+
+    scoreSum := 0.0
+    scored := 0
+    for _, char := range keys {
+        ca, ok := accuracies[char]
+        if !ok || ca.Attempts == 0 {
+            continue
+        }
+
+        group.Attempts += ca.Attempts
+        group.Correct += ca.Correct
+        group.TotalTime += ca.TotalTime
+        scoreSum += ca.Score
+        scored++
+    }
+
+    if scored > 0 {
+        group.Score = scoreSum / float64(scored)
+    }
+
+    return group
+}
@@ -0,0 +1,77 @@
+package multiplayer
+
+import (
+	"net"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// Client is a player joined to a race hosted by another instance.
+type Client struct {
+    OnLesson    func(Lesson)                    // Called with the lesson of a race when the host starts it
+    OnUpdate    func([]shared.RaceProgress)     // Called whenever the progress of any participant changes
+    OnClose     func(error)                     // Called when the connection to the host is lost
+
+    conn        *connection
+}
+
+
+// Join connects to the host at the given address and joins the race with
+// the given name.
+func Join(addr, name string) (*Client, error) {
+    conn, err := net.Dial("tcp", addr)
+    if err != nil {
+        return nil, err
+    }
+
+    c := &Client{ conn: newConnection(conn) }
+    if err := c.conn.send(Message{ Type: MessageJoin, Name: name }); err != nil {
+        conn.Close()
+        return nil, err
+    }
+
+    return c, nil
+}
+
+
+// Listen receives messages from the host until the connection is closed.
+// The callbacks should be set before calling Listen.
+func (c *Client) Listen() {
+    for {
+        msg, err := c.conn.receive()
+        if err != nil {
+            if c.OnClose != nil {
+                c.OnClose(err)
+            }
+            return
+        }
+
+        switch msg.Type {
+        case MessageLesson:
+            if c.OnLesson != nil {
+                c.OnLesson(Lesson{
+                    Words: msg.Words,
+                    Seed: msg.Seed,
+                    Characters: msg.Characters,
+                    Targets: msg.Targets,
+                })
+            }
+        case MessageState:
+            if c.OnUpdate != nil {
+                c.OnUpdate(msg.Players)
+            }
+        }
+    }
+}
+
+
+// UpdateProgress sends the progress of the local player to the host.
+func (c *Client) UpdateProgress(progress shared.RaceProgress) error {
+    return c.conn.send(Message{ Type: MessageProgress, Progress: progress })
+}
+
+
+// Close leaves the race.
+func (c *Client) Close() error {
+    return c.conn.conn.Close()
+}
@@ -0,0 +1,265 @@
+package multiplayer
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// Host hosts a race over TCP. The host is a participant in the race itself
+// and decides when each race starts and which words are used.
+type Host struct {
+    OnUpdate    func([]shared.RaceProgress)     // Called whenever the progress of any participant changes
+
+    listener    net.Listener
+    mu          sync.Mutex
+    lesson      Lesson                          // The lesson of the current race, without words before the first race
+    local       shared.RaceProgress             // The progress of the hosting player
+    clients     []*client                       // The joined clients in the order they joined
+}
+
+
+// outboxSize is the amount of messages queued for a client before it is
+// considered stalled and disconnected.
+const outboxSize = 64
+
+// writeTimeout is how long sending a single message to a client may take
+// before it is disconnected.
+const writeTimeout = 5 * time.Second
+
+
+// client is a player joined to the host. Messages are sent to the client by
+// its own goroutine so that a stalled client never blocks the host.
+type client struct {
+    conn        *connection
+    progress    shared.RaceProgress
+    outbox      chan Message                    // The messages waiting to be sent to the client
+}
+
+
+// newClient creates a client for the connection and starts the goroutine
+// sending its messages.
+func newClient(conn *connection, name string) *client {
+    c := &client{
+        conn: conn,
+        progress: shared.RaceProgress{ Name: name },
+        outbox: make(chan Message, outboxSize),
+    }
+    go c.writeMessages()
+
+    return c
+}
+
+
+// queue queues a message to be sent to the client without blocking. The
+// client is disconnected if too many messages are already waiting.
+func (c *client) queue(msg Message) {
+    select {
+    case c.outbox <- msg:
+    default:
+        c.conn.conn.Close()
+    }
+}
+
+
+// writeMessages sends the queued messages to the client until the outbox is
+// closed. The client is disconnected if a message can not be sent in time.
+func (c *client) writeMessages() {
+    for msg := range c.outbox {
+        c.conn.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+        if err := c.conn.send(msg); err != nil {
+            c.conn.conn.Close()
+        }
+    }
+}
+
+
+// NewHost starts listening for players on the given address. The hosting
+// player is added to the race with the given name.
+func NewHost(addr, name string) (*Host, error) {
+    listener, err := net.Listen("tcp", addr)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Host{
+        listener: listener,
+        local: shared.RaceProgress{ Name: name },
+    }, nil
+}
+
+
+// Addr returns the address the host is listening on.
+func (h *Host) Addr() net.Addr {
+    return h.listener.Addr()
+}
+
+
+// Serve accepts players until the host is closed.
+func (h *Host) Serve() error {
+    for {
+        conn, err := h.listener.Accept()
+        if err != nil {
+            return err
+        }
+
+        go h.handleClient(newConnection(conn))
+    }
+}
+
+
+// StartRace resets the progress of every participant and sends the lesson
+// of the new race to all clients.
+func (h *Host) StartRace(lesson Lesson) {
+    h.mu.Lock()
+    h.lesson = lesson
+    h.local = shared.RaceProgress{ Name: h.local.Name, Total: utf8.RuneCountInString(lesson.Words) - 1 }
+    for _, c := range h.clients {
+        c.progress = shared.RaceProgress{ Name: c.progress.Name, Total: utf8.RuneCountInString(lesson.Words) - 1 }
+        c.queue(lessonMessage(lesson))
+    }
+    h.mu.Unlock()
+
+    h.broadcastState()
+}
+
+
+// UpdateProgress updates the progress of the hosting player and sends the
+// new state to all clients.
+func (h *Host) UpdateProgress(progress shared.RaceProgress) error {
+    h.mu.Lock()
+    progress.Name = h.local.Name
+    h.local = progress
+    h.mu.Unlock()
+
+    h.broadcastState()
+
+    return nil
+}
+
+
+// Players returns the progress of every participant with the host first.
+func (h *Host) Players() []shared.RaceProgress {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    return h.players()
+}
+
+
+// Close stops accepting players and disconnects all clients.
+func (h *Host) Close() error {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    for _, c := range h.clients {
+        c.conn.conn.Close()
+    }
+
+    return h.listener.Close()
+}
+
+
+// players returns the progress of every participant. The caller must hold h.mu.
+func (h *Host) players() []shared.RaceProgress {
+    players := make([]shared.RaceProgress, 0, len(h.clients) + 1)
+    players = append(players, h.local)
+    for _, c := range h.clients {
+        players = append(players, c.progress)
+    }
+
+    return players
+}
+
+
+// uniqueName returns name, or name with a number appended if another
+// participant already uses it. The caller must hold h.mu.
+func (h *Host) uniqueName(name string) string {
+    taken := make(map[string]bool)
+    for _, player := range h.players() {
+        taken[player.Name] = true
+    }
+
+    unique := name
+    for i := 2; taken[unique]; i++ {
+        unique = fmt.Sprintf("%s (%d)", name, i)
+    }
+
+    return unique
+}
+
+
+// handleClient receives messages from a client until it disconnects. The
+// first message must be a join message carrying the name of the player.
+func (h *Host) handleClient(conn *connection) {
+    defer conn.conn.Close()
+
+    msg, err := conn.receive()
+    if err != nil || msg.Type != MessageJoin {
+        return
+    }
+
+    h.mu.Lock()
+    c := newClient(conn, h.uniqueName(msg.Name))
+
+    // Players joining in the middle of a race are sent the current lesson so
+    // they may still take part
+    if h.lesson.Words != "" {
+        c.progress.Total = utf8.RuneCountInString(h.lesson.Words) - 1
+        c.queue(lessonMessage(h.lesson))
+    }
+    h.clients = append(h.clients, c)
+    h.mu.Unlock()
+
+    h.broadcastState()
+
+    for {
+        msg, err := conn.receive()
+        if err != nil {
+            break
+        }
+
+        if msg.Type == MessageProgress {
+            h.mu.Lock()
+            msg.Progress.Name = c.progress.Name
+            c.progress = msg.Progress
+            h.mu.Unlock()
+
+            h.broadcastState()
+        }
+    }
+
+    // Messages are only queued while holding h.mu, so the outbox may be
+    // closed once the client is removed
+    h.mu.Lock()
+    for i, other := range h.clients {
+        if other == c {
+            h.clients = append(h.clients[:i], h.clients[i+1:]...)
+            break
+        }
+    }
+    close(c.outbox)
+    h.mu.Unlock()
+
+    h.broadcastState()
+}
+
+
+// broadcastState queues the progress of every participant for all clients
+// and notifies the hosting player. Never blocks on the clients.
+func (h *Host) broadcastState() {
+    h.mu.Lock()
+    players := h.players()
+    for _, c := range h.clients {
+        c.queue(Message{ Type: MessageState, Players: players })
+    }
+    h.mu.Unlock()
+
+    if h.OnUpdate != nil {
+        h.OnUpdate(players)
+    }
+}
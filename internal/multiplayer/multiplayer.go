@@ -0,0 +1,103 @@
+// Package multiplayer contains functionality for racing other players over the local
+// network. One instance hosts the race over TCP and the others join it. Messages are
+// sent as a stream of JSON objects in both directions.
+package multiplayer
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// Message types sent between the host and the clients.
+const (
+    MessageJoin     = "join"        // Sent by a client when it connects, carries the name of the player
+    MessageLesson   = "lesson"      // Sent by the host when a race starts, carries the words of the race
+    MessageProgress = "progress"    // Sent by a client whenever it makes progress in the race
+    MessageState    = "state"       // Sent by the host whenever the progress of any participant changes
+)
+
+// DefaultPort is the port a race is hosted on if no other is given.
+const DefaultPort = "4242"
+
+
+// Message is a single message sent between the host and a client.
+type Message struct {
+    Type        string                  `json:"type"`                   // The type of the message
+    Name        string                  `json:"name,omitempty"`         // The name of the player joining
+    Words       string                  `json:"words,omitempty"`        // The words of the race
+    Seed        int64                   `json:"seed,omitempty"`         // The seed the words of the race were generated from
+    Characters  string                  `json:"characters,omitempty"`   // The characters the words of the race were generated from
+    Targets     string                  `json:"targets,omitempty"`      // The priority characters the words of the race target
+    Progress    shared.RaceProgress     `json:"progress"`               // The progress of the client sending the message
+    Players     []shared.RaceProgress   `json:"players,omitempty"`      // The progress of every participant in the race
+}
+
+
+// Lesson is the lesson typed in a race, together with what it was generated
+// from so that every participant practises the same characters.
+type Lesson struct {
+    Words       string      // The words of the race
+    Seed        int64       // The seed the words were generated from
+    Characters  string      // The characters the words were generated from
+    Targets     string      // The priority characters the words target
+}
+
+
+// lessonMessage returns the message starting a race with the lesson.
+func lessonMessage(lesson Lesson) Message {
+    return Message{
+        Type: MessageLesson,
+        Words: lesson.Words,
+        Seed: lesson.Seed,
+        Characters: lesson.Characters,
+        Targets: lesson.Targets,
+    }
+}
+
+
+// Participant is the local player's side of a race, either as the host or
+// as a client joined to a host.
+type Participant interface {
+    UpdateProgress(progress shared.RaceProgress) error  // Reports the progress of the local player
+    Close() error                                       // Leaves the race
+}
+
+
+// connection wraps a TCP connection with an encoder and decoder for messages.
+// Sending is safe for concurrent use.
+type connection struct {
+    conn        net.Conn
+    encoder     *json.Encoder
+    decoder     *json.Decoder
+    mu          sync.Mutex
+}
+
+
+func newConnection(conn net.Conn) *connection {
+    return &connection{
+        conn: conn,
+        encoder: json.NewEncoder(conn),
+        decoder: json.NewDecoder(conn),
+    }
+}
+
+
+// send sends a message over the connection.
+func (c *connection) send(msg Message) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    return c.encoder.Encode(msg)
+}
+
+
+// receive blocks until a message is received on the connection.
+func (c *connection) receive() (Message, error) {
+    var msg Message
+    err := c.decoder.Decode(&msg)
+
+    return msg, err
+}
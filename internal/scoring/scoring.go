@@ -0,0 +1,246 @@
+// Package scoring contains the strategies for scoring how well the player knows a
+// character. Every strategy blends an accuracy score and a speed score, but they
+// differ in how much old attempts count compared to recent ones.
+package scoring
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// Names of the built-in strategies.
+const (
+    Linear      = "linear"      // Lifetime accuracy and average time
+    EMA         = "ema"         // Exponential moving averages over the attempt history
+    Windowed    = "windowed"    // Accuracy and average time of the last attempts
+    Bayesian    = "bayesian"    // Pessimistic estimates which become confident with more attempts
+)
+
+// MaxHistory is the max amount of attempts kept in the history of each character.
+const MaxHistory = 500
+
+// MaxTargetCPM is the highest target CPM, at which a character is expected to
+// take a single millisecond. Higher targets would expect no time at all.
+const MaxTargetCPM = 60000
+
+
+// Settings are the settings shared by all strategies.
+type Settings struct {
+    TargetCPM       int         // The target "characters per minute" used for scoring speed
+    AccuracyWeight  float64     // The weight at which accuracy affects the final score
+    TimeWeight      float64     // The weight at which speed affects the final score
+    Alpha           float64     // The smoothing factor of the EMA strategy, higher values favour recent attempts
+    Window          int         // The amount of recent attempts used by the windowed strategy
+}
+
+
+// Scorer scores how well the player knows a character given its accuracy.
+type Scorer interface {
+    Score(ca shared.CharacterAccuracy) float64
+}
+
+
+// New returns the built-in strategy with the given name.
+func New(name string, settings Settings) (Scorer, error) {
+    if settings.TargetCPM <= 0 || settings.TargetCPM > MaxTargetCPM {
+        return nil, fmt.Errorf("the target CPM must be between 1 and %d, got %d", MaxTargetCPM, settings.TargetCPM)
+    }
+
+    switch name {
+    case Linear, "":
+        return linearScorer{ settings }, nil
+    case EMA:
+        if settings.Alpha <= 0 || settings.Alpha > 1 {
+            return nil, fmt.Errorf("the EMA alpha must be in (0, 1], got %v", settings.Alpha)
+        }
+        return emaScorer{ settings }, nil
+    case Windowed:
+        if settings.Window < 1 {
+            return nil, fmt.Errorf("the score window must be positive, got %d", settings.Window)
+        }
+        return windowedScorer{ settings }, nil
+    case Bayesian:
+        return bayesianScorer{ settings }, nil
+    }
+
+    return nil, fmt.Errorf("unknown scoring strategy %q, expected %s, %s, %s or %s", name, Linear, EMA, Windowed, Bayesian)
+}
+
+
+// AddAttempt appends an attempt to the history, dropping the oldest
+// attempts when the history is full.
+func AddAttempt(history []shared.Attempt, attempt shared.Attempt) []shared.Attempt {
+    history = append(history, attempt)
+    if len(history) > MaxHistory {
+        history = history[len(history)-MaxHistory:]
+    }
+
+    return history
+}
+
+
+// linearScorer blends the lifetime accuracy and average time of the
+// character. Every attempt counts equally no matter how old it is.
+type linearScorer struct {
+    settings    Settings
+}
+
+
+func (s linearScorer) Score(ca shared.CharacterAccuracy) float64 {
+    return s.settings.blend(ca.Accuracy, float64(ca.AverageTime))
+}
+
+
+// emaScorer blends exponential moving averages of the accuracy and time of
+// the attempts in the history, so old mistakes decay.
+type emaScorer struct {
+    settings    Settings
+}
+
+
+func (s emaScorer) Score(ca shared.CharacterAccuracy) float64 {
+    if len(ca.History) == 0 {
+        return linearScorer{ s.settings }.Score(ca)
+    }
+
+    accuracy := -1.0
+    time := -1.0
+    for _, attempt := range ca.History {
+        correct := 0.0
+        if attempt.Correct {
+            correct = 1.0
+        }
+
+        if accuracy < 0 {
+            accuracy = correct
+        } else {
+            accuracy = s.settings.Alpha * correct + (1 - s.settings.Alpha) * accuracy
+        }
+
+        if attempt.Correct && attempt.Time >= 0 {
+            if time < 0 {
+                time = float64(attempt.Time)
+            } else {
+                time = s.settings.Alpha * float64(attempt.Time) + (1 - s.settings.Alpha) * time
+            }
+        }
+    }
+
+    if time < 0 {
+        time = 0
+    }
+
+    return s.settings.blend(accuracy, time)
+}
+
+
+// windowedScorer blends the accuracy and average time of the most recent
+// attempts only.
+type windowedScorer struct {
+    settings    Settings
+}
+
+
+func (s windowedScorer) Score(ca shared.CharacterAccuracy) float64 {
+    if len(ca.History) == 0 {
+        return linearScorer{ s.settings }.Score(ca)
+    }
+
+    window := ca.History
+    if len(window) > s.settings.Window {
+        window = window[len(window)-s.settings.Window:]
+    }
+
+    correct, timed := 0, 0
+    totalTime := int64(0)
+    for _, attempt := range window {
+        if attempt.Correct {
+            correct++
+            if attempt.Time >= 0 {
+                timed++
+                totalTime += attempt.Time
+            }
+        }
+    }
+
+    time := 0.0
+    if timed > 0 {
+        time = float64(totalTime) / float64(timed)
+    }
+
+    return s.settings.blend(float64(correct) / float64(len(window)), time)
+}
+
+
+// bayesianScorer blends pessimistic estimates of the accuracy and time.
+// The accuracy is the lower bound of a credible interval of a Beta
+// posterior, and the time is shrunk towards the target time, so a character
+// needs many good attempts before it is trusted.
+type bayesianScorer struct {
+    settings    Settings
+}
+
+
+// bayesianZ is the amount of standard deviations the accuracy estimate is
+// lowered by, roughly a one-sided 90% interval.
+const bayesianZ = 1.28
+
+// bayesianPriorAttempts is how many attempts at the target time the time
+// estimate starts from.
+const bayesianPriorAttempts = 5
+
+
+func (s bayesianScorer) Score(ca shared.CharacterAccuracy) float64 {
+    attempts := float64(ca.Attempts)
+    correct := float64(ca.Correct)
+    totalTime := float64(ca.TotalTime)
+    timed := float64(ca.Correct)
+    if len(ca.History) > 0 {
+        attempts, correct, totalTime, timed = 0, 0, 0, 0
+        for _, attempt := range ca.History {
+            attempts++
+            if attempt.Correct {
+                correct++
+                if attempt.Time >= 0 {
+                    timed++
+                    totalTime += float64(attempt.Time)
+                }
+            }
+        }
+    }
+
+    // Beta(1, 1) prior updated with the correct and incorrect attempts
+    alpha := correct + 1
+    beta := attempts - correct + 1
+    mean := alpha / (alpha + beta)
+    variance := (alpha * beta) / ((alpha + beta) * (alpha + beta) * (alpha + beta + 1))
+    accuracy := math.Max(mean - bayesianZ * math.Sqrt(variance), 0)
+
+    time := (totalTime + bayesianPriorAttempts * s.settings.targetTime()) / (timed + bayesianPriorAttempts)
+
+    return s.settings.blend(accuracy, time)
+}
+
+
+// targetTime returns the target time per character in milliseconds.
+func (s Settings) targetTime() float64 {
+    return float64(60000 / s.TargetCPM)
+}
+
+
+// blend returns the weighted blend of the accuracy and the speed score of
+// the given time per character in milliseconds. Times at or below half the
+// target time give full speed score, and the target time gives none.
+func (s Settings) blend(accuracy, time float64) float64 {
+    targetSpeedMs := 60000 / s.TargetCPM
+    lowerBound := targetSpeedMs / 2
+    speed := int64(time)
+    if speed < int64(lowerBound) {
+        speed = int64(lowerBound)
+    }
+    speedScore := 1 - (float64(speed - int64(lowerBound)) / float64(targetSpeedMs - lowerBound))
+
+    return (accuracy * s.AccuracyWeight) + (speedScore * s.TimeWeight)
+}
@@ -0,0 +1,119 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// testSettings expects 300 ms per character, so times of 150 ms or less get
+// full speed score and slower times than the target lower the score further.
+var testSettings = Settings{ TargetCPM: 200, AccuracyWeight: 0.5, TimeWeight: 0.5, Alpha: 0.5, Window: 2 }
+
+
+func TestNew(t *testing.T) {
+    tests := []struct {
+        name        string
+        strategy    string
+        settings    Settings
+        wantErr     bool
+    }{
+        { name: "default strategy", strategy: "", settings: testSettings },
+        { name: "bayesian strategy", strategy: Bayesian, settings: testSettings },
+        { name: "highest target CPM", strategy: Linear, settings: Settings{ TargetCPM: MaxTargetCPM } },
+        { name: "no target CPM", strategy: Linear, settings: Settings{ TargetCPM: 0 }, wantErr: true },
+        { name: "target CPM too high", strategy: Linear, settings: Settings{ TargetCPM: MaxTargetCPM + 1 }, wantErr: true },
+        { name: "no EMA alpha", strategy: EMA, settings: Settings{ TargetCPM: 200 }, wantErr: true },
+        { name: "EMA alpha too high", strategy: EMA, settings: Settings{ TargetCPM: 200, Alpha: 1.5 }, wantErr: true },
+        { name: "no window", strategy: Windowed, settings: Settings{ TargetCPM: 200 }, wantErr: true },
+        { name: "unknown strategy", strategy: "random", settings: testSettings, wantErr: true },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            _, err := New(test.strategy, test.settings)
+            if (err != nil) != test.wantErr {
+                t.Errorf("New(%q, %+v) error = %v, want error %t", test.strategy, test.settings, err, test.wantErr)
+            }
+        })
+    }
+}
+
+
+func TestScore(t *testing.T) {
+    history := []shared.Attempt{
+        { Correct: false, Time: -1 },
+        { Correct: true, Time: 300 },
+        { Correct: true, Time: 150 },
+    }
+
+    tests := []struct {
+        name        string
+        strategy    string
+        ca          shared.CharacterAccuracy
+        want        float64
+    }{
+        { name: "linear at full speed", strategy: Linear, ca: shared.CharacterAccuracy{ Accuracy: 1, AverageTime: 100 }, want: 1 },
+        { name: "linear at the target time", strategy: Linear, ca: shared.CharacterAccuracy{ Accuracy: 1, AverageTime: 300 }, want: 0.5 },
+        { name: "linear between", strategy: Linear, ca: shared.CharacterAccuracy{ Accuracy: 0.5, AverageTime: 225 }, want: 0.5 },
+        { name: "linear slower than the target", strategy: Linear, ca: shared.CharacterAccuracy{ Accuracy: 1, AverageTime: 450 }, want: 0 },
+        { name: "EMA", strategy: EMA, ca: shared.CharacterAccuracy{ History: history }, want: 0.625 },
+        { name: "EMA without history", strategy: EMA, ca: shared.CharacterAccuracy{ Accuracy: 1, AverageTime: 300 }, want: 0.5 },
+        { name: "windowed", strategy: Windowed, ca: shared.CharacterAccuracy{ History: history }, want: 0.75 },
+        { name: "windowed without history", strategy: Windowed, ca: shared.CharacterAccuracy{ Accuracy: 1, AverageTime: 300 }, want: 0.5 },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            scorer, err := New(test.strategy, testSettings)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if got := scorer.Score(test.ca); math.Abs(got - test.want) > 1e-9 {
+                t.Errorf("Score(%+v) = %v, want %v", test.ca, got, test.want)
+            }
+        })
+    }
+}
+
+
+// TestBayesianScore checks that the bayesian strategy trusts a character
+// more the more good attempts it has, without reaching a perfect score.
+func TestBayesianScore(t *testing.T) {
+    scorer, err := New(Bayesian, testSettings)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    previous := 0.0
+    for _, attempts := range []int64{ 0, 1, 10, 100 } {
+        ca := shared.CharacterAccuracy{ Correct: attempts, Attempts: attempts, TotalTime: attempts * 150 }
+        score := scorer.Score(ca)
+        if score <= previous || score >= 1 {
+            t.Errorf("Score() with %d good attempts = %v, want between %v and 1", attempts, score, previous)
+        }
+        previous = score
+    }
+}
+
+
+// TestScoreHighestTargetCPM checks that the scores are finite at the
+// highest target CPM.
+func TestScoreHighestTargetCPM(t *testing.T) {
+    settings := testSettings
+    settings.TargetCPM = MaxTargetCPM
+    ca := shared.CharacterAccuracy{ Correct: 1, Attempts: 1, Accuracy: 1, TotalTime: 0, AverageTime: 0, History: []shared.Attempt{ { Correct: true, Time: 0 } } }
+
+    for _, strategy := range []string{ Linear, EMA, Windowed, Bayesian } {
+        scorer, err := New(strategy, settings)
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        if score := scorer.Score(ca); math.IsNaN(score) || math.IsInf(score, 0) {
+            t.Errorf("%s Score(%+v) = %v, want a finite score", strategy, ca, score)
+        }
+    }
+}
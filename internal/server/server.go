@@ -0,0 +1,248 @@
+// Package server exposes the game engine over a local HTTP API so that other
+// frontends may use the same scoring and persistence as the TUI. Requests and
+// responses are JSON encoded. A minimal web UI playing lessons over a WebSocket
+// is bundled with the server.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/Kaspetti/LayoutLearner/internal/gamelogic"
+	"github.com/Kaspetti/LayoutLearner/internal/layout"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+// maxRequestSize is the max size of a request body in bytes.
+const maxRequestSize = 1 << 20
+
+
+// errorResponse is the body of every response to a failed request.
+type errorResponse struct {
+    Error       string      `json:"error"`      // A description of what went wrong
+}
+
+
+// The game engine keeps its state globally, so requests are handled one at a time.
+var engineMu sync.Mutex
+
+
+// withEngine calls f while holding engineMu. The lock is released even if f
+// panics, so that a failed request does not block every later one.
+func withEngine(f func()) {
+    engineMu.Lock()
+    defer engineMu.Unlock()
+
+    f()
+}
+
+
+// Serve loads the game and serves the API on the given address until it fails.
+func Serve(addr string) error {
+    if err := gamelogic.LoadGame(); err != nil {
+        return err
+    }
+
+    log.Printf("serving LayoutLearner on http://%s\n", addr)
+    return http.ListenAndServe(addr, NewHandler())
+}
+
+
+// NewHandler returns the handler serving the web UI and the API routes:
+//
+//  GET  /              serves the web UI
+//  GET  /ws            plays lessons with the web UI over a WebSocket
+//  POST /api/lesson    generates a lesson from gamelogic.LessonParams
+//  POST /api/results   scores the gamelogic.LessonResults of a finished lesson
+//  GET  /api/stats     returns the accuracy of each character
+//  GET  /api/history   returns the finished lessons, oldest first
+//  GET  /api/layout    returns the keyboard layout in the settings
+func NewHandler() http.Handler {
+    mux := http.NewServeMux()
+    mux.Handle("/", staticHandler())
+    mux.HandleFunc("/ws", handleWebsocket)
+    mux.HandleFunc("/api/lesson", handleLesson)
+    mux.HandleFunc("/api/results", handleResults)
+    mux.HandleFunc("/api/stats", handleStats)
+    mux.HandleFunc("/api/history", handleHistory)
+    mux.HandleFunc("/api/layout", handleLayout)
+
+    return mux
+}
+
+
+func handleLesson(w http.ResponseWriter, r *http.Request) {
+    if !allowMethod(w, r, http.MethodPost) {
+        return
+    }
+
+    if !requireJSON(w, r) {
+        return
+    }
+
+    var params gamelogic.LessonParams
+    if err := decodeBody(w, r, &params); err != nil {
+        writeError(w, http.StatusBadRequest, err)
+        return
+    }
+
+    var lesson gamelogic.Lesson
+    var err error
+    withEngine(func() {
+        lesson, err = gamelogic.GenerateLesson(params)
+    })
+    if err != nil {
+        writeError(w, http.StatusBadRequest, err)
+        return
+    }
+
+    writeJSON(w, http.StatusOK, lesson)
+}
+
+
+func handleResults(w http.ResponseWriter, r *http.Request) {
+    if !allowMethod(w, r, http.MethodPost) {
+        return
+    }
+
+    if !requireJSON(w, r) {
+        return
+    }
+
+    var results gamelogic.LessonResults
+    if err := decodeBody(w, r, &results); err != nil {
+        writeError(w, http.StatusBadRequest, err)
+        return
+    }
+
+    var record shared.SessionRecord
+    var err error
+    withEngine(func() {
+        record, err = gamelogic.SubmitResults(results)
+    })
+    if err != nil {
+        writeError(w, http.StatusBadRequest, err)
+        return
+    }
+
+    writeJSON(w, http.StatusOK, record)
+}
+
+
+func handleStats(w http.ResponseWriter, r *http.Request) {
+    if !allowMethod(w, r, http.MethodGet) {
+        return
+    }
+
+    var stats map[string]shared.CharacterAccuracy
+    withEngine(func() {
+        stats = characterStats()
+    })
+
+    writeJSON(w, http.StatusOK, stats)
+}
+
+
+// characterStats returns the accuracy of each character. Runes would be
+// encoded as numbers when used as map keys, so the characters are converted
+// to strings. The caller must hold engineMu.
+func characterStats() map[string]shared.CharacterAccuracy {
+    stats := gamelogic.CharacterStats()
+
+    response := make(map[string]shared.CharacterAccuracy, len(stats))
+    for char, ca := range stats {
+        response[string(char)] = ca
+    }
+
+    return response
+}
+
+
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+    if !allowMethod(w, r, http.MethodGet) {
+        return
+    }
+
+    var sessions []shared.SessionRecord
+    withEngine(func() {
+        sessions = gamelogic.SessionHistory()
+    })
+
+    writeJSON(w, http.StatusOK, sessions)
+}
+
+
+func handleLayout(w http.ResponseWriter, r *http.Request) {
+    if !allowMethod(w, r, http.MethodGet) {
+        return
+    }
+
+    var keyboardLayout layout.Layout
+    withEngine(func() {
+        keyboardLayout = gamelogic.KeyboardLayout()
+    })
+
+    writeJSON(w, http.StatusOK, keyboardLayout)
+}
+
+
+// allowMethod writes a 405 response and returns false if the request does
+// not use the given method.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+    if r.Method != method {
+        w.Header().Set("Allow", method)
+        writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+        return false
+    }
+
+    return true
+}
+
+
+// requireJSON writes a 415 response and returns false if the body of the
+// request is not declared as JSON. Browsers only send JSON to other origins
+// after a CORS preflight, which the server never allows, so this stops other
+// web pages from posting forms to the API.
+func requireJSON(w http.ResponseWriter, r *http.Request) bool {
+    mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+    if err != nil || mediaType != "application/json" {
+        writeError(w, http.StatusUnsupportedMediaType, errors.New("expected Content-Type application/json"))
+        return false
+    }
+
+    return true
+}
+
+
+// decodeBody decodes the JSON body of the request into v. An empty body
+// leaves v unchanged.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+    decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+    decoder.DisallowUnknownFields()
+
+    if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+        return err
+    }
+
+    return nil
+}
+
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+
+    if err := json.NewEncoder(w).Encode(v); err != nil {
+        log.Println(err)
+    }
+}
+
+
+func writeError(w http.ResponseWriter, status int, err error) {
+    writeJSON(w, status, errorResponse{ Error: err.Error() })
+}
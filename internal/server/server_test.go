@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaspetti/LayoutLearner/internal/gamelogic"
+)
+
+// loadTestGame loads a game in a profile kept in a temporary directory which
+// picks its words from a small dictionary.
+func loadTestGame(t *testing.T) {
+    t.Helper()
+
+    dir := t.TempDir()
+    wd, err := os.Getwd()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chdir(dir); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { os.Chdir(wd) })
+
+    if err := gamelogic.SetProfile("test"); err != nil {
+        t.Fatal(err)
+    }
+
+    dictionaryPath := filepath.Join(dir, "words.txt")
+    if err := os.WriteFile(dictionaryPath, []byte("the\nsea\nare\nrise\nraise\nease\nseat\nrate\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    settings := fmt.Sprintf(`{ "dictionaryPath": %q, "numChars": 4 }`, dictionaryPath)
+    if err := os.WriteFile(filepath.Join("profiles", "test", "settings.json"), []byte(settings), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := gamelogic.LoadGame(); err != nil {
+        t.Fatal(err)
+    }
+}
+
+
+// TestInvalidResults checks that invalid results are rejected and that the
+// server keeps handling requests afterwards.
+func TestInvalidResults(t *testing.T) {
+    loadTestGame(t)
+
+    // Close waits for the requests being handled, so it is skipped after a
+    // failure where a request may be stuck waiting for the engine
+    server := httptest.NewServer(NewHandler())
+    t.Cleanup(func() {
+        if !t.Failed() {
+            server.Close()
+        }
+    })
+    client := &http.Client{ Timeout: 5 * time.Second }
+
+    tests := []struct {
+        name        string
+        body        string
+    }{
+        {
+            name: "fewer keystrokes than characters",
+            body: `{ "words": "the sea rise ", "keystrokes": [ { "expected": "t", "typed": "t", "time": 100 } ] }`,
+        },
+        {
+            name: "more keystrokes than characters",
+            body: `{ "words": "a ", "keystrokes": [ { "expected": "a", "typed": "a", "time": 100 }, { "expected": " ", "typed": " ", "time": 100 } ] }`,
+        },
+        {
+            name: "keystroke expecting another character",
+            body: `{ "words": "at ", "keystrokes": [ { "expected": "a", "typed": "a", "time": 100 }, { "expected": "s", "typed": "s", "time": 100 } ] }`,
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            resp, err := client.Post(server.URL + "/api/results", "application/json", strings.NewReader(test.body))
+            if err != nil {
+                t.Fatal(err)
+            }
+            resp.Body.Close()
+            if resp.StatusCode != http.StatusBadRequest {
+                t.Errorf("POST /api/results returned %d, want %d", resp.StatusCode, http.StatusBadRequest)
+            }
+
+            resp, err = client.Get(server.URL + "/api/stats")
+            if err != nil {
+                t.Fatalf("GET /api/stats after invalid results: %v", err)
+            }
+            resp.Body.Close()
+            if resp.StatusCode != http.StatusOK {
+                t.Errorf("GET /api/stats returned %d, want %d", resp.StatusCode, http.StatusOK)
+            }
+        })
+    }
+}
@@ -0,0 +1,260 @@
+// The web UI of LayoutLearner. Lessons are generated and every keystroke is
+// scored by the server over a WebSocket, so the scoring is the same as in the
+// terminal game.
+"use strict";
+
+const mainView = document.getElementById("main");
+const infoView = document.getElementById("info");
+const keyboardView = document.getElementById("keyboard");
+
+// The color stops of the score gradient, matching the terminal game
+const colorStops = [[231, 72, 86], [249, 241, 165], [59, 120, 255]];
+
+const state = {
+    lesson: null,
+    colors: [],
+    index: 0,
+    stats: {},
+    finished: false,
+    lastKeyTime: null,
+    layout: null,
+};
+
+// The heatmap shows the keyboard layout in the settings
+fetch("/api/layout")
+    .then((response) => response.json())
+    .then((layout) => {
+        state.layout = layout;
+        if (state.lesson) {
+            drawKeyboard();
+        }
+    })
+    .catch(() => showError("the keyboard layout could not be loaded"));
+
+const socket = new WebSocket(`ws://${location.host}/ws`);
+
+socket.addEventListener("open", () => newLesson());
+socket.addEventListener("close", () => showError("the connection to the server was lost"));
+socket.addEventListener("message", (event) => {
+    const msg = JSON.parse(event.data);
+    if (msg.stats) {
+        state.stats = msg.stats;
+    }
+
+    switch (msg.type) {
+    case "lesson":
+        startLesson(msg.lesson);
+        break;
+    case "key":
+        // Corrected characters are shown in yellow
+        if (!msg.correct) {
+            state.colors[msg.index] = "red";
+        } else {
+            state.colors[msg.index] = msg.firstTry ? "blue" : "yellow";
+        }
+        state.index = msg.next;
+        draw();
+        break;
+    case "backspace":
+        state.colors[msg.index] = "white";
+        state.index = msg.index;
+        draw();
+        break;
+    case "end":
+        state.finished = true;
+        showEndScreen(msg.record);
+        break;
+    case "error":
+        showError(msg.error);
+        break;
+    }
+});
+
+document.addEventListener("keydown", (event) => {
+    if (event.ctrlKey || event.altKey || event.metaKey) {
+        return;
+    }
+
+    if (state.finished) {
+        if (event.key === "Enter") {
+            newLesson();
+        } else if (event.key === "p") {
+            newLesson("problemWords");
+        }
+        return;
+    }
+
+    if (!state.lesson) {
+        return;
+    }
+
+    if (event.key === "Backspace") {
+        event.preventDefault();
+        socket.send(JSON.stringify({ type: "backspace" }));
+        return;
+    }
+
+    if (event.key.length !== 1) {
+        return;
+    }
+    event.preventDefault();
+
+    // The time of a character is measured from the previous keystroke
+    const now = performance.now();
+    const time = state.lastKeyTime === null ? 0 : Math.round(now - state.lastKeyTime);
+    state.lastKeyTime = now;
+
+    socket.send(JSON.stringify({ type: "key", key: event.key, time: time }));
+});
+
+function newLesson(type) {
+    socket.send(JSON.stringify({ type: "lesson", params: { type: type || "" } }));
+}
+
+function startLesson(lesson) {
+    state.lesson = lesson;
+    state.colors = Array.from(lesson.words, () => "white");
+    state.index = 0;
+    state.finished = false;
+    state.lastKeyTime = null;
+    draw();
+    mainView.focus();
+}
+
+// interpolateColor returns the color of a score between 0 and 1 on the
+// gradient from red through yellow to blue.
+function interpolateColor(t) {
+    t = Math.min(Math.max(t, 0), 1);
+
+    let from = colorStops[0];
+    let to = colorStops[1];
+    if (t >= 0.5) {
+        t = (t - 0.5) * 2;
+        from = colorStops[1];
+        to = colorStops[2];
+    }
+
+    const channels = from.map((c, i) => Math.floor(c * (1 - t) + to[i] * t));
+    return `rgb(${channels.join(",")})`;
+}
+
+function scoreColor(char) {
+    const ca = state.stats[char];
+    if (!ca || ca.score === -1) {
+        return null;
+    }
+
+    return interpolateColor(ca.score);
+}
+
+function span(text, className, color) {
+    const element = document.createElement("span");
+    element.textContent = text;
+    if (className) {
+        element.className = className;
+    }
+    if (color) {
+        element.style.color = color;
+    }
+
+    return element;
+}
+
+function draw() {
+    drawText();
+    drawInfo();
+    drawKeyboard();
+}
+
+function drawText() {
+    mainView.replaceChildren();
+
+    const words = state.lesson.words;
+    for (let i = 0; i < words.length; i++) {
+        let className = state.colors[i];
+        if (words[i] === " " && i < words.length - 1) {
+            className += " space";
+        }
+        if (i === state.index) {
+            className += " cursor";
+        }
+
+        mainView.append(span(words[i], className));
+    }
+}
+
+function drawInfo() {
+    infoView.replaceChildren();
+
+    if (state.lesson.courseStage) {
+        infoView.append(span("Stage: ", "yellow"), span(`${state.lesson.courseStage}\n\n`));
+    }
+
+    infoView.append(span("Accuracy:\n", "yellow"));
+    const chars = Array.from(state.lesson.characters);
+    chars.forEach((char, i) => {
+        infoView.append(span(char, null, scoreColor(char) || "#fff"));
+        if (i < chars.length - 1) {
+            infoView.append(span("|"));
+        }
+    });
+
+    infoView.append(span("\n\nPriority: ", "yellow"));
+    for (const char of state.lesson.priorityCharacters) {
+        infoView.append(span(`${char} `, null, scoreColor(char) || "#fff"));
+    }
+
+    infoView.append(span("\n\nAverage times:", "yellow"));
+    for (const [char, ca] of Object.entries(state.stats)) {
+        const time = ca.averageTime >= 1000 ? `${(ca.averageTime / 1000).toFixed(2)}s` : `${ca.averageTime}ms`;
+        infoView.append(span(`\n${char}=${time}`));
+    }
+
+    infoView.append(span("\n\nScores:", "yellow"));
+    for (const [char, ca] of Object.entries(state.stats)) {
+        infoView.append(span(`\n${char}=${ca.score.toFixed(2)}`));
+    }
+}
+
+function drawKeyboard() {
+    keyboardView.replaceChildren();
+    if (!state.layout) {
+        return;
+    }
+
+    state.layout.rows.forEach((row, i) => {
+        const rowElement = document.createElement("div");
+        rowElement.className = "row";
+        rowElement.style.marginLeft = `${i * 0.8}em`;
+
+        for (const char of row) {
+            const key = span(char, "key");
+            const color = scoreColor(char);
+            if (color) {
+                key.style.background = color;
+            } else {
+                key.style.color = "#888";
+            }
+            rowElement.append(key);
+        }
+
+        keyboardView.append(rowElement);
+    });
+}
+
+function showEndScreen(record) {
+    drawInfo();
+    drawKeyboard();
+
+    mainView.replaceChildren(
+        span(`Your accuracy was: ${(record.accuracy * 100).toFixed(2)}\n`, "white"),
+        span(`Your speed was: ${Math.round(record.cpm)} CPM\n`, "white"),
+        span(`First try errors: ${record.firstTryErrors} (${record.corrected} corrected)\n`, "white"),
+        span("Press enter to continue\n", "yellow"),
+        span("Press p to practice problem words\n", "yellow"),
+    );
+}
+
+function showError(error) {
+    mainView.replaceChildren(span(`An error occured.\n\n${error}`, "red"));
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"net/http"
+
+	"github.com/Kaspetti/LayoutLearner/internal/gamelogic"
+	"github.com/Kaspetti/LayoutLearner/internal/shared"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+
+// clientMessage is a message sent by the web UI over the WebSocket.
+type clientMessage struct {
+    Type        string                  `json:"type"`       // lesson, key or backspace
+    Params      gamelogic.LessonParams  `json:"params"`     // The parameters of the lesson to generate
+    Key         string                  `json:"key"`        // The character typed
+    Time        int64                   `json:"time"`       // The time since the previous keystroke in milliseconds
+}
+
+// serverMessage is a message sent to the web UI over the WebSocket.
+type serverMessage struct {
+    Type        string                                  `json:"type"`               // lesson, key, backspace, end or error
+    Lesson      *gamelogic.Lesson                       `json:"lesson,omitempty"`   // The generated lesson
+    Index       int                                     `json:"index"`              // The index of the character the message is about
+    Next        int                                     `json:"next"`               // The index of the character in play after the message
+    Correct     bool                                    `json:"correct"`            // If the keystroke was correct
+    FirstTry    bool                                    `json:"firstTry"`           // If the character was typed correctly on the first try
+    Record      *shared.SessionRecord                   `json:"record,omitempty"`   // The record of the finished lesson
+    Stats       map[string]shared.CharacterAccuracy     `json:"stats,omitempty"`    // The accuracy of each character
+    Error       string                                  `json:"error,omitempty"`    // A description of what went wrong
+}
+
+
+// staticHandler serves the embedded web UI.
+func staticHandler() http.Handler {
+    files, err := fs.Sub(staticFiles, "static")
+    if err != nil {
+        log.Fatalln(err)
+    }
+
+    return http.FileServer(http.FS(files))
+}
+
+
+// handleWebsocket plays lessons with the web UI. Each keystroke is scored by
+// the game engine as it arrives, and the result is sent back along with the
+// updated character stats.
+func handleWebsocket(w http.ResponseWriter, r *http.Request) {
+    conn, err := upgradeWebsocket(w, r)
+    if err != nil {
+        log.Println(err)
+        return
+    }
+    defer conn.Close()
+
+    var session *gamelogic.LessonSession
+    for {
+        data, err := conn.ReadMessage()
+        if err != nil {
+            return
+        }
+
+        var msg clientMessage
+        if err := json.Unmarshal(data, &msg); err != nil {
+            sendMessage(conn, serverMessage{ Type: "error", Error: err.Error() })
+            continue
+        }
+
+        var response serverMessage
+        withEngine(func() {
+            response = handleClientMessage(msg, &session)
+        })
+
+        if err := sendMessage(conn, response); err != nil {
+            return
+        }
+    }
+}
+
+
+// handleClientMessage handles a message from the web UI and returns the
+// response. The caller must hold engineMu.
+func handleClientMessage(msg clientMessage, session **gamelogic.LessonSession) serverMessage {
+    switch msg.Type {
+    case "lesson":
+        lesson, err := gamelogic.GenerateLesson(msg.Params)
+        if err != nil {
+            return serverMessage{ Type: "error", Error: err.Error() }
+        }
+
+        *session = gamelogic.NewLessonSession(lesson)
+        return serverMessage{ Type: "lesson", Lesson: &lesson, Stats: characterStats() }
+
+    case "key":
+        if *session == nil {
+            return serverMessage{ Type: "error", Error: "no lesson has been started" }
+        }
+
+        typed := []rune(msg.Key)
+        if len(typed) != 1 {
+            return serverMessage{ Type: "error", Error: "expected a single character" }
+        }
+
+        index := (*session).Index
+        correct, record, err := (*session).Keystroke(typed[0], msg.Time)
+        if err != nil {
+            return serverMessage{ Type: "error", Error: err.Error() }
+        }
+
+        response := serverMessage{
+            Type: "key",
+            Index: index,
+            Next: (*session).Index,
+            Correct: correct,
+            FirstTry: (*session).FirstTry(index),
+            Stats: characterStats(),
+        }
+        if record != nil {
+            response.Type = "end"
+            response.Record = record
+        }
+        return response
+
+    case "backspace":
+        if *session == nil {
+            return serverMessage{ Type: "error", Error: "no lesson has been started" }
+        }
+
+        (*session).Backspace()
+        return serverMessage{ Type: "backspace", Index: (*session).Index, Next: (*session).Index }
+    }
+
+    return serverMessage{ Type: "error", Error: "unknown message type " + msg.Type }
+}
+
+
+func sendMessage(conn *websocketConn, msg serverMessage) error {
+    data, err := json.Marshal(msg)
+    if err != nil {
+        return err
+    }
+
+    return conn.WriteMessage(data)
+}
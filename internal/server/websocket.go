@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// websocketGUID is appended to the key of the client when accepting a
+// WebSocket handshake as described in RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxMessageSize is the max size of a message received over a WebSocket in bytes.
+const maxMessageSize = 1 << 16
+
+// WebSocket frame opcodes.
+const (
+    opContinuation  = 0x0
+    opText          = 0x1
+    opBinary        = 0x2
+    opClose         = 0x8
+    opPing          = 0x9
+    opPong          = 0xA
+)
+
+
+var errMessageTooLarge = errors.New("websocket message too large")
+
+
+// websocketConn is the server side of a WebSocket connection. Only the parts
+// of RFC 6455 needed by the web UI are supported: unfragmented text messages
+// are sent, and text messages, pings and close frames are received.
+type websocketConn struct {
+    conn        net.Conn
+    reader      *bufio.Reader
+    mu          sync.Mutex
+}
+
+
+// upgradeWebsocket completes the WebSocket handshake of the request and takes
+// over its connection. Upgrades from pages served by other hosts are rejected
+// so that other web pages can not play lessons in the name of the player.
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+    if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+        http.Error(w, "expected a websocket upgrade", http.StatusBadRequest)
+        return nil, errors.New("request is not a websocket upgrade")
+    }
+
+    if !sameOrigin(r) {
+        http.Error(w, "cross-origin websocket upgrades are not allowed", http.StatusForbidden)
+        return nil, fmt.Errorf("rejected websocket upgrade from origin %q", r.Header.Get("Origin"))
+    }
+
+    key := r.Header.Get("Sec-WebSocket-Key")
+    if key == "" {
+        http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+        return nil, errors.New("missing Sec-WebSocket-Key")
+    }
+
+    hijacker, ok := w.(http.Hijacker)
+    if !ok {
+        http.Error(w, "websockets are not supported", http.StatusInternalServerError)
+        return nil, errors.New("response writer can not be hijacked")
+    }
+
+    conn, rw, err := hijacker.Hijack()
+    if err != nil {
+        return nil, err
+    }
+
+    hash := sha1.Sum([]byte(key + websocketGUID))
+    accept := base64.StdEncoding.EncodeToString(hash[:])
+
+    rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+    rw.WriteString("Upgrade: websocket\r\n")
+    rw.WriteString("Connection: Upgrade\r\n")
+    rw.WriteString("Sec-WebSocket-Accept: " + accept + "\r\n\r\n")
+    if err := rw.Flush(); err != nil {
+        conn.Close()
+        return nil, err
+    }
+
+    return &websocketConn{ conn: conn, reader: rw.Reader }, nil
+}
+
+
+// ReadMessage blocks until a complete text message is received. Pings are
+// answered while waiting. Returns io.EOF when the client closes the connection.
+func (c *websocketConn) ReadMessage() ([]byte, error) {
+    var message []byte
+    for {
+        fin, opcode, payload, err := c.readFrame()
+        if err != nil {
+            return nil, err
+        }
+
+        switch opcode {
+        case opClose:
+            c.writeFrame(opClose, nil)
+            return nil, io.EOF
+        case opPing:
+            if err := c.writeFrame(opPong, payload); err != nil {
+                return nil, err
+            }
+            continue
+        case opPong:
+            continue
+        }
+
+        message = append(message, payload...)
+        if len(message) > maxMessageSize {
+            return nil, errMessageTooLarge
+        }
+
+        if fin {
+            return message, nil
+        }
+    }
+}
+
+
+// WriteMessage sends a text message. Safe for concurrent use.
+func (c *websocketConn) WriteMessage(message []byte) error {
+    return c.writeFrame(opText, message)
+}
+
+
+// Close closes the underlying connection.
+func (c *websocketConn) Close() error {
+    return c.conn.Close()
+}
+
+
+// readFrame reads a single frame sent by the client. Frames from the client
+// are always masked.
+func (c *websocketConn) readFrame() (bool, byte, []byte, error) {
+    header := make([]byte, 2)
+    if _, err := io.ReadFull(c.reader, header); err != nil {
+        return false, 0, nil, err
+    }
+
+    fin := header[0] & 0x80 != 0
+    opcode := header[0] & 0x0F
+    masked := header[1] & 0x80 != 0
+    length := uint64(header[1] & 0x7F)
+
+    switch length {
+    case 126:
+        extended := make([]byte, 2)
+        if _, err := io.ReadFull(c.reader, extended); err != nil {
+            return false, 0, nil, err
+        }
+        length = uint64(binary.BigEndian.Uint16(extended))
+    case 127:
+        extended := make([]byte, 8)
+        if _, err := io.ReadFull(c.reader, extended); err != nil {
+            return false, 0, nil, err
+        }
+        length = binary.BigEndian.Uint64(extended)
+    }
+
+    if length > maxMessageSize {
+        return false, 0, nil, errMessageTooLarge
+    }
+    if !masked {
+        return false, 0, nil, errors.New("received an unmasked websocket frame")
+    }
+
+    mask := make([]byte, 4)
+    if _, err := io.ReadFull(c.reader, mask); err != nil {
+        return false, 0, nil, err
+    }
+
+    payload := make([]byte, length)
+    if _, err := io.ReadFull(c.reader, payload); err != nil {
+        return false, 0, nil, err
+    }
+    for i := range payload {
+        payload[i] ^= mask[i%4]
+    }
+
+    if opcode != opContinuation && opcode != opText && opcode != opBinary &&
+        opcode != opClose && opcode != opPing && opcode != opPong {
+        return false, 0, nil, errors.New("received an unknown websocket opcode")
+    }
+
+    return fin, opcode, payload, nil
+}
+
+
+// writeFrame sends a single unfragmented frame. Frames from the server are
+// never masked.
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    frame := []byte{ 0x80 | opcode }
+    switch {
+    case len(payload) < 126:
+        frame = append(frame, byte(len(payload)))
+    case len(payload) <= 0xFFFF:
+        frame = append(frame, 126, 0, 0)
+        binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+    default:
+        frame = append(frame, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+        binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+    }
+    frame = append(frame, payload...)
+
+    _, err := c.conn.Write(frame)
+    return err
+}
+
+
+// sameOrigin returns if the Origin of the request has the same host as the
+// request. Requests without an Origin do not come from a browser and are
+// allowed.
+func sameOrigin(r *http.Request) bool {
+    origin := r.Header.Get("Origin")
+    if origin == "" {
+        return true
+    }
+
+    u, err := url.Parse(origin)
+    if err != nil {
+        return false
+    }
+
+    return strings.EqualFold(u.Host, r.Host)
+}
+
+
+// headerContains returns true if the comma separated values of the header
+// contain the given token, ignoring case.
+func headerContains(header http.Header, name, token string) bool {
+    for _, value := range header.Values(name) {
+        for _, part := range strings.Split(value, ",") {
+            if strings.EqualFold(strings.TrimSpace(part), token) {
+                return true
+            }
+        }
+    }
+
+    return false
+}
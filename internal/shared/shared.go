@@ -12,4 +12,71 @@ type CharacterAccuracy struct {
     TotalTime   int64       `json:"totalTime"`      // The total time spent on all attempts in milliseconds
     AverageTime int64       `json:"averageTime"`    // The average time spent per attempt in milliseconds
     Score       float64     `json:"score"`          // The total score of the character considering accuracy and time
+    History     []Attempt   `json:"history"`        // The most recent attempts at the character, oldest first
+}
+
+
+// Attempt stores a single attempt at typing a character
+type Attempt struct {
+    Correct     bool        `json:"correct"`        // If the attempt was correct
+    Time        int64       `json:"time"`           // The time spent on the attempt in milliseconds, -1 if it was not timed
+}
+
+
+// WordStats stores how well the player types a word over every lesson the
+// word was in
+type WordStats struct {
+    Attempts    int         `json:"attempts"`       // The amount of times the word has been typed
+    Errors      int         `json:"errors"`         // The amount of characters of the word typed wrong on the first try
+    Corrections int         `json:"corrections"`    // The amount of those errors which were corrected afterwards
+    TotalTime   int64       `json:"totalTime"`      // The total time spent typing the word in milliseconds
+    LastTyped   int64       `json:"lastTyped"`      // The last time the word was typed in milliseconds since unix
+}
+
+
+// RaceProgress stores the progress of a single participant in a multiplayer race
+type RaceProgress struct {
+    Name        string      `json:"name"`           // The name of the participant
+    Index       int         `json:"index"`          // The index of the character the participant is currently on
+    Total       int         `json:"total"`          // The amount of characters in the race
+    Correct     int         `json:"correct"`        // The amount of correctly written characters
+    Incorrect   int         `json:"incorrect"`      // The amount of incorrectly written characters
+    Finished    bool        `json:"finished"`       // Finished becomes true when the participant reaches the end of the words
+    Time        int64       `json:"time"`           // The time spent on the race in milliseconds
+}
+
+
+// SessionRecord stores the result of a single finished lesson
+type SessionRecord struct {
+    Time            int64       `json:"time"`            // The time the lesson was finished in milliseconds since unix
+    Words           string      `json:"words"`           // The words of the lesson
+    Correct         int         `json:"correct"`         // The amount of correctly written characters
+    Incorrect       int         `json:"incorrect"`       // The amount of incorrectly written characters
+    Duration        int64       `json:"duration"`        // The time from the first to the last keystroke in milliseconds
+    CPM             float64     `json:"cpm"`             // The characters per minute of the lesson
+    Accuracy        float64     `json:"accuracy"`        // The accuracy of the lesson (Correct / (Correct + Incorrect))
+    Seed            int64       `json:"seed"`            // The seed the words of the lesson were generated from, 0 if unknown
+    FirstTryErrors  int         `json:"firstTryErrors"`  // The amount of characters typed wrong on the first try
+    Corrected       int         `json:"corrected"`       // The amount of characters typed wrong on the first try and corrected afterwards
+    Source          *LessonSource `json:"source,omitempty"` // What the words of the lesson were generated from, nil if unknown
+    Keystrokes      []KeystrokeRecord `json:"keystrokes,omitempty"` // Every keystroke of the lesson in the order they were made
+}
+
+
+// KeystrokeRecord stores a single keystroke made in a lesson
+type KeystrokeRecord struct {
+    Expected        string      `json:"expected"`        // The character the player should have typed
+    Typed           string      `json:"typed"`           // The character the player typed
+    Retry           bool        `json:"retry,omitempty"` // If the character had been typed wrong before, so a correct keystroke corrects it
+}
+
+
+// LessonSource stores what the words of a lesson were generated from, so that
+// the lesson can be generated again from its seed
+type LessonSource struct {
+    Type            string      `json:"type"`            // The type of the lesson, empty for lessons generated from the characters and targets
+    Characters      string      `json:"characters"`      // The characters the words were made of
+    Targets         string      `json:"targets"`         // The priority characters the words targeted
+    MinWordLength   int         `json:"minWordLength"`   // The min word length (inclusive)
+    MaxWordLength   int         `json:"maxWordLength"`   // The max word length (inclusive)
 }
@@ -0,0 +1,124 @@
+// Package srs contains a spaced-repetition scheduler based on SM-2. Each item, such as
+// a character or a bigram, is reviewed after every lesson it appears in, and the
+// quality of the review decides how long it takes until the item is due again.
+package srs
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Bounds and the starting value of the ease factor as described by SM-2.
+const (
+    initialEaseFactor   = 2.5
+    minEaseFactor       = 1.3
+)
+
+// passingQuality is the lowest review quality which counts as remembered.
+const passingQuality = 3
+
+// day is the length of an interval unit.
+const day = 24 * time.Hour
+
+
+// Item stores the review state of a single item.
+type Item struct {
+    EaseFactor  float64     `json:"easeFactor"`     // How quickly the interval grows after successful reviews
+    Interval    int         `json:"interval"`       // The amount of days until the item is due after the last review
+    Repetitions int         `json:"repetitions"`    // The amount of successful reviews in a row
+    Due         int64       `json:"due"`            // The time the item is due in milliseconds since unix
+    LastReview  int64       `json:"lastReview"`     // The time of the last review in milliseconds since unix
+}
+
+
+// Schedule stores the review state of every reviewed item by its key.
+type Schedule map[string]Item
+
+
+// Review updates the item given the quality of a review from 0 (failed
+// completely) to 5 (perfect). Failed items are due again immediately so they
+// are drilled until they pass.
+func (item Item) Review(quality int, now time.Time) Item {
+    if item.EaseFactor == 0 {
+        item.EaseFactor = initialEaseFactor
+    }
+
+    if quality < passingQuality {
+        item.Repetitions = 0
+        item.Interval = 0
+    } else {
+        item.Repetitions++
+        switch item.Repetitions {
+        case 1:
+            item.Interval = 1
+        case 2:
+            item.Interval = 6
+        default:
+            item.Interval = int(math.Round(float64(item.Interval) * item.EaseFactor))
+        }
+    }
+
+    q := float64(5 - quality)
+    item.EaseFactor += 0.1 - q * (0.08 + q * 0.02)
+    if item.EaseFactor < minEaseFactor {
+        item.EaseFactor = minEaseFactor
+    }
+
+    item.LastReview = now.UnixMilli()
+    item.Due = now.Add(time.Duration(item.Interval) * day).UnixMilli()
+
+    return item
+}
+
+
+// Review reviews the item with the given key, adding it to the schedule if
+// it has not been reviewed before.
+func (s Schedule) Review(key string, quality int, now time.Time) {
+    s[key] = s[key].Review(quality, now)
+}
+
+
+// Due returns the keys among the candidates which are due, most overdue
+// first. Candidates which have never been reviewed are due before all others.
+func (s Schedule) Due(candidates []string, now time.Time) []string {
+    due := make([]string, 0)
+    for _, key := range candidates {
+        if item, ok := s[key]; !ok || item.Due <= now.UnixMilli() {
+            due = append(due, key)
+        }
+    }
+
+    sort.SliceStable(due, func(i, j int) bool {
+        return s[due[i]].Due < s[due[j]].Due
+    })
+
+    return due
+}
+
+
+// Quality returns the quality of a review from the accuracy of the attempts
+// and their average time compared to the target time. Each step below
+// perfect accuracy lowers the quality, and being slower than the target
+// lowers it by one more.
+func Quality(accuracy, averageTime, targetTime float64) int {
+    quality := 0
+    switch {
+    case accuracy >= 0.98:
+        quality = 5
+    case accuracy >= 0.95:
+        quality = 4
+    case accuracy >= 0.9:
+        quality = 3
+    case accuracy >= 0.8:
+        quality = 2
+    case accuracy >= 0.6:
+        quality = 1
+    }
+
+    if averageTime > targetTime && quality > 0 {
+        quality--
+    }
+
+    return quality
+}
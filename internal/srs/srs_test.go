@@ -0,0 +1,93 @@
+package srs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestItemReview(t *testing.T) {
+    now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+    tests := []struct {
+        name            string
+        qualities       []int
+        wantInterval    int
+        wantRepetitions int
+        wantEaseFactor  float64
+    }{
+        { name: "first pass", qualities: []int{ 4 }, wantInterval: 1, wantRepetitions: 1, wantEaseFactor: 2.5 },
+        { name: "second pass", qualities: []int{ 4, 4 }, wantInterval: 6, wantRepetitions: 2, wantEaseFactor: 2.5 },
+        { name: "third pass", qualities: []int{ 4, 4, 4 }, wantInterval: 15, wantRepetitions: 3, wantEaseFactor: 2.5 },
+        { name: "perfect passes", qualities: []int{ 5, 5, 5 }, wantInterval: 16, wantRepetitions: 3, wantEaseFactor: 2.8 },
+        { name: "failure after passes", qualities: []int{ 4, 4, 2 }, wantInterval: 0, wantRepetitions: 0, wantEaseFactor: 2.18 },
+        { name: "ease factor bound", qualities: []int{ 0, 0, 0, 0, 0 }, wantInterval: 0, wantRepetitions: 0, wantEaseFactor: 1.3 },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            var item Item
+            for _, quality := range test.qualities {
+                item = item.Review(quality, now)
+            }
+
+            if item.Interval != test.wantInterval || item.Repetitions != test.wantRepetitions {
+                t.Errorf("interval %d and repetitions %d, want %d and %d", item.Interval, item.Repetitions, test.wantInterval, test.wantRepetitions)
+            }
+            if diff := item.EaseFactor - test.wantEaseFactor; diff > 1e-9 || diff < -1e-9 {
+                t.Errorf("ease factor %f, want %f", item.EaseFactor, test.wantEaseFactor)
+            }
+            if want := now.Add(time.Duration(test.wantInterval) * day).UnixMilli(); item.Due != want {
+                t.Errorf("due %d, want %d", item.Due, want)
+            }
+            if item.LastReview != now.UnixMilli() {
+                t.Errorf("last review %d, want %d", item.LastReview, now.UnixMilli())
+            }
+        })
+    }
+}
+
+
+func TestScheduleDue(t *testing.T) {
+    now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    schedule := Schedule{
+        "a": { Due: now.Add(-time.Hour).UnixMilli() },
+        "b": { Due: now.Add(-2 * day).UnixMilli() },
+        "c": { Due: now.Add(time.Hour).UnixMilli() },
+        "d": { Due: now.UnixMilli() },
+    }
+
+    got := schedule.Due([]string{ "a", "b", "c", "d", "e" }, now)
+    want := []string{ "e", "b", "a", "d" }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("Due() = %q, want %q", got, want)
+    }
+
+    if got := schedule.Due([]string{ "c" }, now); len(got) != 0 {
+        t.Errorf("Due() = %q, want none", got)
+    }
+}
+
+
+func TestQuality(t *testing.T) {
+    tests := []struct {
+        accuracy    float64
+        averageTime float64
+        want        int
+    }{
+        { accuracy: 1, averageTime: 100, want: 5 },
+        { accuracy: 0.96, averageTime: 100, want: 4 },
+        { accuracy: 0.9, averageTime: 100, want: 3 },
+        { accuracy: 0.85, averageTime: 100, want: 2 },
+        { accuracy: 0.6, averageTime: 100, want: 1 },
+        { accuracy: 0.5, averageTime: 100, want: 0 },
+        { accuracy: 1, averageTime: 300, want: 4 },
+        { accuracy: 0.5, averageTime: 300, want: 0 },
+    }
+
+    for _, test := range tests {
+        if got := Quality(test.accuracy, test.averageTime, 200); got != test.want {
+            t.Errorf("Quality(%v, %v, 200) = %d, want %d", test.accuracy, test.averageTime, got, test.want)
+        }
+    }
+}